@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"time"
 )
@@ -8,21 +9,79 @@ import (
 type vkAPI struct {
 	URLs  []string `env-default:"https://api.vk.com,https://api.vk.ru"`
 	Token string   `env:"TOKEN" env-default:"<TOKEN HERE>"`
+	// Tokens Пул сервисных токенов через запятую. Если задан, используется вместо Token.
+	Tokens []string `env:"TOKENS" env-separator:","`
+	// Quarantine Время исключения токена из ротации после ошибки 5 или 29.
+	Quarantine time.Duration `env:"TOKEN_QUARANTINE" env-default:"10m"`
+	// PageSize Размер страницы для users.getFollowers/users.getSubscriptions.
+	PageSize int `env:"PAGE_SIZE" env-default:"1000"`
+	// MaxItems Ограничение на число подписчиков/подписок одного пользователя, 0 - без ограничения.
+	MaxItems int `env:"MAX_ITEMS" env-default:"0"`
+	// RPS Ограничение числа запросов в секунду на токен, 0 - без ограничения.
+	RPS   float64 `env:"RPS" env-default:"3"`
+	Burst int     `env:"BURST" env-default:"1"`
+}
+
+// AllTokens Токены для пула VK клиента.
+func (c vkAPI) AllTokens() []string {
+	if len(c.Tokens) > 0 {
+		return c.Tokens
+	}
+
+	return []string{c.Token}
 }
 
 type API struct {
 	Port string `env:"PORT" env-default:":8080"`
 }
 
+// neo4j Все переменные с префиксом NEO4J_. Прежние имена без префикса (URL, DB_NAME, WRITE_BATCH_SIZE,
+// TX_MAX_RETRY_TIME) читаются, если новые не заданы.
 type neo4j struct {
-	URL    string `env:"URL" env-default:"bolt://localhost:7687"`
-	DBName string `env:"DB_NAME" env-default:"nizamov_vk"`
+	URL    string `env:"NEO4J_URL,URL" env-default:"bolt://localhost:7687"`
+	DBName string `env:"NEO4J_DB_NAME,DB_NAME" env-default:"nizamov_vk"`
+	// BatchSize Число узлов или связей в одном UNWIND запросе при пакетной записи.
+	BatchSize int `env:"NEO4J_WRITE_BATCH_SIZE,WRITE_BATCH_SIZE" env-default:"1000"`
+	// TxRetryTime Сколько драйвер повторяет транзакцию записи при временных ошибках.
+	TxRetryTime time.Duration `env:"NEO4J_TX_MAX_RETRY_TIME,TX_MAX_RETRY_TIME" env-default:"30s"`
+
+	// AuthScheme Схема аутентификации: none, basic (NEO4J_USERNAME, NEO4J_PASSWORD, NEO4J_REALM) или bearer (NEO4J_TOKEN).
+	AuthScheme string `env:"NEO4J_AUTH" env-default:"none"`
+	Username   string `env:"NEO4J_USERNAME"`
+	Password   string `env:"NEO4J_PASSWORD"`
+	Realm      string `env:"NEO4J_REALM"`
+	Token      string `env:"NEO4J_TOKEN"`
+	// CACertFile PEM файл с корневыми сертификатами для схем neo4j+s:// и bolt+s://.
+	CACertFile string `env:"NEO4J_CA_CERT"`
+	// MaxPoolSize Максимальное число соединений в пуле драйвера.
+	MaxPoolSize        int           `env:"NEO4J_MAX_POOL_SIZE" env-default:"100"`
+	AcquisitionTimeout time.Duration `env:"NEO4J_ACQUISITION_TIMEOUT" env-default:"60s"`
 }
 
+type crawler struct {
+	Depth     int `env:"CRAWLER_DEPTH" env-default:"2"`
+	Workers   int `env:"CRAWLER_WORKERS" env-default:"4"`
+	BatchSize int `env:"CRAWLER_BATCH_SIZE" env-default:"12"`
+	// FanOut Ограничение числа соседей пользователя по уровням обхода через запятую, 0 - без ограничения.
+	FanOut   []int `env:"CRAWLER_FAN_OUT" env-separator:"," env-default:"0"`
+	MaxUsers int   `env:"CRAWLER_MAX_USERS" env-default:"0"`
+	// CheckpointDir Каталог контрольных точек задач обхода.
+	CheckpointDir      string        `env:"CRAWLER_CHECKPOINT_DIR" env-default:"crawls"`
+	CheckpointInterval time.Duration `env:"CRAWLER_CHECKPOINT_INTERVAL" env-default:"5s"`
+}
+
+const (
+	StorageNeo4j  = "neo4j"
+	StorageMemory = "memory"
+)
+
 type Config struct {
-	API            API
-	VKAPI          vkAPI
+	API   API
+	VKAPI vkAPI
+	// Storage Хранилище графа: neo4j или memory (данные теряются при перезапуске).
+	Storage        string `env:"STORAGE" env-default:"neo4j"`
 	Neo4j          neo4j
+	Crawler        crawler
 	ContextTimeout time.Duration `env:"TIMEOUT" env-default:"60s"`
 }
 
@@ -32,5 +91,9 @@ func NewConfig() (*Config, error) {
 		return &Config{}, err
 	}
 
+	if cfg.Storage != StorageNeo4j && cfg.Storage != StorageMemory {
+		return &Config{}, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+
 	return &cfg, nil
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/Nimartemoff/vk-api/cmd/vk-api/config"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/app"
 	"github.com/rs/zerolog/log"
+	"os"
 )
 
 func main() {
@@ -13,6 +14,22 @@ func main() {
 		log.Fatal().Err(err).Msg("could not read env")
 	}
 
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			if err := app.Export(cfg, os.Args[2:]); err != nil {
+				log.Fatal().Err(err).Msg("could not export graph")
+			}
+			return
+		case "import":
+			if err := app.Import(cfg, os.Args[2:]); err != nil {
+				log.Fatal().Err(err).Msg("could not import graph")
+			}
+			return
+		}
+	}
+
 	// Run
 	app.Run(cfg)
 }
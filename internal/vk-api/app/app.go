@@ -5,38 +5,51 @@ import (
 	"github.com/Nimartemoff/vk-api/cmd/vk-api/config"
 	v1 "github.com/Nimartemoff/vk-api/internal/vk-api/controller/http/v1"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/crawler"
+	memoryRepo "github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
 	neo4jRepo "github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/neo4j"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
 	"github.com/Nimartemoff/vk-api/pkg/httpserver"
 	"github.com/go-chi/chi"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/rs/zerolog/log"
 )
 
-const (
-	userID   = 183170347
-	fileName = "Копырин.json"
-)
-
 func Run(cfg *config.Config) {
-	c := rest.NewVKClient(cfg.VKAPI.URLs, cfg.VKAPI.Token)
+	c := rest.NewVKClient(cfg.VKAPI.URLs, cfg.VKAPI.AllTokens(),
+		rest.WithPageSize(cfg.VKAPI.PageSize),
+		rest.WithMaxItems(cfg.VKAPI.MaxItems),
+		rest.WithRateLimit(cfg.VKAPI.RPS, cfg.VKAPI.Burst),
+		rest.WithQuarantine(cfg.VKAPI.Quarantine),
+	)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ContextTimeout)
 	defer cancel()
 
-	driver, err := neo4j.NewDriverWithContext(cfg.Neo4j.URL, neo4j.NoAuth())
+	repo, closeRepo, err := newRepository(cfg)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+	defer closeRepo()
+
+	crawlStore, err := crawler.NewFileStore(cfg.Crawler.CheckpointDir)
 	if err != nil {
 		log.Error().Err(err).Send()
 		return
 	}
-	defer driver.Close(ctx)
 
-	session := driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: "neo4j",
-	})
-	defer session.Close(ctx)
+	crawls := crawler.NewManager(context.Background(), c, repo, crawlStore, crawler.Config{
+		Depth:     cfg.Crawler.Depth,
+		Workers:   cfg.Crawler.Workers,
+		BatchSize: cfg.Crawler.BatchSize,
+		FanOut:    cfg.Crawler.FanOut,
+		MaxUsers:  cfg.Crawler.MaxUsers,
+	}, cfg.Crawler.CheckpointInterval)
+	if err := crawls.Restore(ctx); err != nil {
+		log.Error().Err(err).Msg("could not restore crawl jobs")
+	}
 
-	userUsecase := usecase.NewUserUsecase(c, neo4jRepo.NewUserNeo4jRepo(session))
+	userUsecase := usecase.NewUserUsecase(c, repo, crawls)
 
 	r := chi.NewRouter()
 	v1.NewRouter(cfg, r, userUsecase)
@@ -48,76 +61,30 @@ func Run(cfg *config.Config) {
 	//if err := userUsecase.CreateIndexes(ctx); err != nil {
 	//	log.Error().Err(err).Send()
 	//}
-	//
-	//user, err := userUsecase.GetUsersWithDepth(userID, 3)
-	//if err != nil {
-	//	log.Error().Err(err).Send()
-	//	return
-	//}
-	//
-	//if err := userUsecase.SaveUser(ctx, user); err != nil {
-	//	log.Error().Err(err).Send()
-	//	return
-	//}
-	//
-	//queryType := flag.String("query_type", "0", "Выбор запроса из параметров командной строки")
-	//flag.Parse()
-	//
-	//switch *queryType {
-	//case "0":
-	//	count, err := userUsecase.GetUsersCount(ctx)
-	//	if err != nil {
-	//		log.Error().Err(err).Send()
-	//		return
-	//	}
-	//
-	//	log.Info().Msgf("Количество пользователей: %d", count)
-	//case "1":
-	//	count, err := userUsecase.GetGroupsCount(ctx)
-	//	if err != nil {
-	//		log.Error().Err(err).Send()
-	//		return
-	//	}
-	//
-	//	log.Info().Msgf("Количество групп: %d", count)
-	//case "2":
-	//	users, err := userUsecase.GetTopUsersByFollowersCount(ctx, 5)
-	//	if err != nil {
-	//		log.Error().Err(err).Send()
-	//		return
-	//	}
-	//
-	//	log.Info().Msgf("Топ 5 пользователей по числу фолловеров: %+v", users)
-	//case "3":
-	//	groups, err := userUsecase.GetTopGroupsBySubscribersCount(ctx, 5)
-	//	if err != nil {
-	//		log.Error().Err(err).Send()
-	//		return
-	//	}
-	//
-	//	log.Info().Msgf("Топ 5 групп по числу подписок: %+v", groups)
-	//case "4":
-	//	users, err := userUsecase.GetUsersWithDifferentGroups(ctx, 5)
-	//	if err != nil {
-	//		log.Error().Err(err).Send()
-	//		return
-	//	}
-	//
-	//	log.Info().Msgf("Пользователи с непересекающимися группами в подписаках: %+v", users)
-	//}
-	//
-	//file, err := os.Create(fileName)
-	//if err != nil {
-	//	fmt.Println(err)
-	//}
-	//defer file.Close()
-	//
-	//encoder := json.NewEncoder(file)
-	//encoder.SetIndent("", "    ")
-	//if err := encoder.Encode(user); err != nil {
-	//	log.Error().Err(err).Send()
-	//	return
-	//}
-	//
-	//log.Info().Msgf("Завершение работы программы")
+}
+
+// newRepository Хранилище графа по конфигурации. closeRepo закрывает соединения с Neo4j.
+func newRepository(cfg *config.Config) (repo usecase.GraphRepository, closeRepo func(), err error) {
+	if cfg.Storage == config.StorageMemory {
+		return memoryRepo.NewUserMemoryRepo(), func() {}, nil
+	}
+
+	neo4jRepository, err := neo4jRepo.NewUserNeo4jRepo(neo4jRepo.Config{
+		URL:                cfg.Neo4j.URL,
+		DBName:             cfg.Neo4j.DBName,
+		AuthScheme:         cfg.Neo4j.AuthScheme,
+		Username:           cfg.Neo4j.Username,
+		Password:           cfg.Neo4j.Password,
+		Realm:              cfg.Neo4j.Realm,
+		Token:              cfg.Neo4j.Token,
+		CACertFile:         cfg.Neo4j.CACertFile,
+		MaxPoolSize:        cfg.Neo4j.MaxPoolSize,
+		AcquisitionTimeout: cfg.Neo4j.AcquisitionTimeout,
+		TxRetryTime:        cfg.Neo4j.TxRetryTime,
+	}, neo4jRepo.WithBatchSize(cfg.Neo4j.BatchSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return neo4jRepository, func() { neo4jRepository.Close(context.Background()) }, nil
 }
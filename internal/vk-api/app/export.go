@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/cmd/vk-api/config"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/export"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Export Подкоманда export: выгружает граф из хранилища, заданного конфигурацией.
+//
+//	vk-api export -format gexf -out graph.gexf -label User -city Surgut -types Follow
+//
+// Без -out документ пишется в stdout, для csv - zip архивом. С -out формат csv пишет в каталог nodes.csv и edges.csv.
+func Export(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", export.FormatGraphML, "формат: "+strings.Join(export.Formats, ", "))
+	out := flags.String("out", "-", "файл, для csv - каталог; - - stdout")
+	label := flags.String("label", "", "только узлы с меткой User или Group")
+	city := flags.String("city", "", "только пользователи из города")
+	sex := flags.Int("sex", -1, "только пользователи пола 0, 1 или 2")
+	types := flags.String("types", "", "типы связей через запятую, по умолчанию Follow,Subscribe")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !slices.Contains(export.Formats, *format) {
+		return fmt.Errorf("%w %q", export.ErrUnknownFormat, *format)
+	}
+
+	filter := models.ExportFilter{Label: *label, City: *city}
+	if *types != "" {
+		filter.Types = strings.Split(*types, ",")
+	}
+	if *sex >= 0 {
+		b := byte(*sex)
+		filter.Sex = &b
+	}
+
+	repo, closeRepo, err := newRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var files []*os.File
+	err = usecase.NewUserUsecase(nil, repo, nil).ExportGraph(ctx, filter, func() (export.Writer, error) {
+		if *out == "-" {
+			return export.NewWriter(*format, os.Stdout)
+		}
+
+		if *format != export.FormatCSV {
+			f, err := os.Create(*out)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+
+			return export.NewWriter(*format, f)
+		}
+
+		if err := os.MkdirAll(*out, 0o755); err != nil {
+			return nil, err
+		}
+
+		for _, name := range []string{export.NodesFileName, export.EdgesFileName} {
+			f, err := os.Create(filepath.Join(*out, name))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+
+		return export.NewCSVWriter(files[0], files[1])
+	})
+
+	for _, f := range files {
+		err = errors.Join(err, f.Close())
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/Nimartemoff/vk-api/cmd/vk-api/config"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/importer"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// Import Подкоманда import: загружает выгрузки JSON и списки связей CSV в хранилище, заданное конфигурацией,
+// и печатает отчет в stdout.
+//
+//	vk-api import -dry-run dump.json edges.csv
+//
+// Формат определяется по расширению файла, -format задает его для всех файлов. Файл - читается из stdin.
+func Import(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "формат всех файлов: "+strings.Join(importer.Formats, ", ")+"; по умолчанию по расширению")
+	dryRun := flags.Bool("dry-run", false, "только проверить файлы, не записывая граф")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("no files to import")
+	}
+
+	var (
+		files  []importer.File
+		opened []*os.File
+	)
+	defer func() {
+		for _, f := range opened {
+			f.Close()
+		}
+	}()
+
+	for _, name := range flags.Args() {
+		if name == "-" {
+			files = append(files, importer.File{Name: "stdin", Format: *format, Reader: os.Stdin})
+			continue
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		opened = append(opened, f)
+
+		files = append(files, importer.File{Name: name, Format: *format, Reader: f})
+	}
+
+	repo, closeRepo, err := newRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Прерванный импорт тоже печатает отчет: в нем видно, что уже записано.
+	report, err := usecase.NewUserUsecase(nil, repo, nil).ImportGraph(ctx, files, *dryRun)
+	if err != nil && !errors.Is(err, usecase.ErrImportInterrupted) {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil {
+		return encodeErr
+	}
+
+	return err
+}
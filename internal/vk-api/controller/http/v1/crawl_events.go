@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/crawler"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseSnapshotEvent     = "snapshot"
+)
+
+// streamCrawlEvents Поток событий задачи обхода в формате Server-Sent Events. Первым отправляется
+// текущее состояние задачи, поток закрывается после остановки задачи. Токен редактора передается
+// заголовком Authorization или, для EventSource в браузере, параметром access_token.
+func (ur *userRoutes) streamCrawlEvents(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	info, events, unsubscribe, err := ur.SubscribeCrawl(id)
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	// Поток живет дольше WriteTimeout сервера.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("could not reset write deadline for event stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeSSE(w, rc, sseSnapshotEvent, info); err != nil || info.Status != crawler.StatusRunning {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			if err := writeSSE(w, rc, string(e.Type), e); err != nil || e.Final() {
+				return
+			}
+		case <-heartbeat.C:
+			// Финальное событие могло потеряться при переполнении буфера подписчика.
+			info, err := ur.GetCrawl(id)
+			if err != nil || info.Status != crawler.StatusRunning {
+				writeSSE(w, rc, sseSnapshotEvent, info)
+				return
+			}
+
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			rc.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, rc *http.ResponseController, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
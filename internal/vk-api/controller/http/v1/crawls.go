@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/crawler"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
+	"github.com/go-chi/chi"
+	"net/http"
+)
+
+type createCrawlRequest struct {
+	// Seeds VK id или короткие имена стартовых пользователей.
+	Seeds     []string `json:"seeds"`
+	Depth     int      `json:"depth"`
+	Workers   int      `json:"workers"`
+	BatchSize int      `json:"batch_size"`
+	FanOut    []int    `json:"fan_out"`
+	MaxUsers  int      `json:"max_users"`
+}
+
+func (ur *userRoutes) createCrawl(w http.ResponseWriter, r *http.Request) {
+	var req createCrawlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(req.Seeds) == 0 {
+		renderError(w, http.StatusBadRequest, fmt.Errorf("empty seeds"))
+		return
+	}
+
+	if req.Depth < 0 || req.Workers < 0 || req.BatchSize < 0 || req.MaxUsers < 0 {
+		renderError(w, http.StatusBadRequest, fmt.Errorf("depth, workers, batch_size and max_users must not be negative"))
+		return
+	}
+
+	job, err := ur.StartCrawl(r.Context(), req.Seeds, crawler.Config{
+		Depth:     req.Depth,
+		Workers:   req.Workers,
+		BatchSize: req.BatchSize,
+		FanOut:    req.FanOut,
+		MaxUsers:  req.MaxUsers,
+	})
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/crawls/"+job.ID)
+	renderJSONStatus(w, http.StatusAccepted, job)
+}
+
+func (ur *userRoutes) getCrawls(w http.ResponseWriter, r *http.Request) {
+	renderJSON(w, ur.ListCrawls())
+}
+
+func (ur *userRoutes) getCrawl(w http.ResponseWriter, r *http.Request) {
+	job, err := ur.GetCrawl(chi.URLParam(r, "id"))
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+
+	renderJSON(w, job)
+}
+
+func (ur *userRoutes) pauseCrawl(w http.ResponseWriter, r *http.Request) {
+	job, err := ur.PauseCrawl(chi.URLParam(r, "id"))
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+
+	renderJSON(w, job)
+}
+
+func (ur *userRoutes) resumeCrawl(w http.ResponseWriter, r *http.Request) {
+	job, err := ur.ResumeCrawl(chi.URLParam(r, "id"))
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+
+	renderJSON(w, job)
+}
+
+func (ur *userRoutes) cancelCrawl(w http.ResponseWriter, r *http.Request) {
+	job, err := ur.CancelCrawl(chi.URLParam(r, "id"))
+	if err != nil {
+		renderCrawlError(w, err)
+		return
+	}
+
+	renderJSON(w, job)
+}
+
+func renderCrawlError(w http.ResponseWriter, err error) {
+	vkErr, isVKErr := rest.AsVKError(err)
+
+	switch {
+	case errors.Is(err, crawler.ErrJobNotFound):
+		renderError(w, http.StatusNotFound, err)
+	case errors.Is(err, crawler.ErrJobNotRunning), errors.Is(err, crawler.ErrJobNotPaused), errors.Is(err, crawler.ErrJobFinished):
+		renderError(w, http.StatusConflict, err)
+	case errors.Is(err, crawler.ErrInvalidConfig), errors.Is(err, usecase.ErrInvalidSeeds),
+		isVKErr && vkErr.Code == rest.ErrCodeInvalidUserID:
+		renderError(w, http.StatusBadRequest, err)
+	case isVKErr:
+		renderError(w, http.StatusBadGateway, err)
+	default:
+		renderError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"net/http"
+	"strconv"
+)
+
+// getEgoNetwork GET /nodes/{type}/{id}/ego?hops=&limit=&types=&direction=
+func (ur *userRoutes) getEgoNetwork(w http.ResponseWriter, r *http.Request) {
+	ref, err := nodeRef(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	params := r.URL.Query()
+	query := models.EgoQuery{Center: ref, Types: typesParam(params), Direction: params.Get("direction")}
+
+	for key, n := range map[string]*int{"hops": &query.Hops, "limit": &query.Limit} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+
+		i, err := strconv.Atoi(value)
+		if err != nil || i <= 0 {
+			renderGraphError(w, fmt.Errorf("%w: invalid %s %q", usecase.ErrInvalidQuery, key, value))
+			return
+		}
+		*n = i
+	}
+
+	subgraph, err := ur.EgoNetwork(r.Context(), query)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, subgraph)
+}
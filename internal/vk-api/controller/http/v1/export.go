@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/export"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// getExport GET /export?format=&label=&city=&sex=&types=
+// Граф передается потоком, format по умолчанию graphml, csv - zip архив с nodes.csv и edges.csv.
+// Ошибка после начала передачи обрывает соединение через http.ErrAbortHandler.
+func (ur *userRoutes) getExport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format := cmp.Or(params.Get("format"), export.FormatGraphML)
+	if !slices.Contains(export.Formats, format) {
+		renderGraphError(w, fmt.Errorf("%w: %w %q", usecase.ErrInvalidQuery, export.ErrUnknownFormat, format))
+		return
+	}
+
+	filter := models.ExportFilter{
+		Label: params.Get("label"),
+		City:  params.Get("city"),
+		Types: typesParam(params),
+	}
+
+	if v := params.Get("sex"); v != "" {
+		sex, err := strconv.ParseUint(v, Base, 8)
+		if err != nil {
+			renderError(w, http.StatusBadRequest, fmt.Errorf("invalid sex: %q", v))
+			return
+		}
+
+		b := byte(sex)
+		filter.Sex = &b
+	}
+
+	started := false
+	err := ur.ExportGraph(r.Context(), filter, func() (export.Writer, error) {
+		// Выгрузка большого графа идет дольше WriteTimeout сервера.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn().Err(err).Msg("could not reset write deadline for export")
+		}
+
+		w.Header().Set("Content-Type", export.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="vk-graph`+export.Extension(format)+`"`)
+		started = true
+
+		return export.NewWriter(format, w)
+	})
+	if err == nil {
+		return
+	}
+
+	if !started {
+		renderGraphError(w, err)
+		return
+	}
+
+	// Статус 200 уже отправлен: обрываем соединение, чтобы клиент не принял
+	// неполную выгрузку за целую.
+	log.Error().Err(err).Msg("graph export interrupted")
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"net/http"
+	"strconv"
+)
+
+func (ur *userRoutes) getGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := ur.ListGroups(r.Context())
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, groups)
+}
+
+func (ur *userRoutes) getGroupByID(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	group, err := ur.FindGroup(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, group)
+}
+
+func (ur *userRoutes) createGroup(w http.ResponseWriter, r *http.Request) {
+	var group models.GroupWithSubscribers
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Подписки создаются только для уже существующих пользователей.
+	created, err := ur.CreateGroup(r.Context(), group)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/groups/"+strconv.FormatUint(created.ID, Base))
+	renderJSONStatus(w, http.StatusCreated, created)
+}
+
+// replaceGroup PUT: свойства группы заменяются телом запроса целиком.
+func (ur *userRoutes) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	var group models.Group
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ur.updateGroup(w, r, id, group)
+}
+
+// patchGroup PATCH: меняются только переданные в теле свойства.
+func (ur *userRoutes) patchGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	group, err := ur.FindGroup(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ur.updateGroup(w, r, id, group)
+}
+
+func (ur *userRoutes) updateGroup(w http.ResponseWriter, r *http.Request, id uint64, group models.Group) {
+	if group.ID != 0 && group.ID != id {
+		renderGraphError(w, fmt.Errorf("%w: body id %d does not match path id %d", usecase.ErrInvalidID, group.ID, id))
+		return
+	}
+	group.ID = id
+
+	updated, err := ur.UpdateGroup(r.Context(), group)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, updated)
+}
+
+func (ur *userRoutes) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	if err := ur.DeleteGroup(r.Context(), id); err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ur *userRoutes) getGroupSubscribers(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	subscribers, err := ur.ListSubscribers(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, subscribers)
+}
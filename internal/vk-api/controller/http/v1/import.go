@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/importer"
+	"github.com/rs/zerolog/log"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxImportSize Предел тела запроса импорта.
+	maxImportSize = 256 << 20
+	// importReadTimeout и importWriteTimeout Сроки чтения тела и ответа импорта вместо таймаутов сервера:
+	// загрузка и запись большого графа идут дольше, но соединение не должно висеть бесконечно.
+	importReadTimeout  = 30 * time.Minute
+	importWriteTimeout = time.Hour
+)
+
+// postImport POST /import?dry_run=&format=
+// Файлы передаются полями multipart/form-data, формат определяется по расширению имени файла,
+// либо один файл телом запроса с форматом из format или Content-Type (application/json, text/csv).
+// Если запись прервалась, отвечает 500 с отчетом о записанном.
+func (ur *userRoutes) postImport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	dryRun := false
+	if v := params.Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			renderError(w, http.StatusBadRequest, fmt.Errorf("invalid dry_run: %q", v))
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Now().Add(importReadTimeout)); err != nil {
+		log.Warn().Err(err).Msg("could not extend read deadline for import")
+	}
+	if err := rc.SetWriteDeadline(time.Now().Add(importWriteTimeout)); err != nil {
+		log.Warn().Err(err).Msg("could not extend write deadline for import")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	files, err := importFiles(r)
+	if err != nil {
+		renderImportError(w, err)
+		return
+	}
+
+	report, err := ur.ImportGraph(r.Context(), files, dryRun)
+	if errors.Is(err, usecase.ErrImportInterrupted) {
+		// Часть графа уже записана: отчет с полем error показывает, что именно.
+		log.Error().Err(err).Msg("graph import interrupted")
+		renderJSONStatus(w, http.StatusInternalServerError, report)
+		return
+	}
+	if err != nil {
+		renderImportError(w, err)
+		return
+	}
+
+	renderJSON(w, report)
+}
+
+// importFiles Файлы из multipart формы в порядке передачи или тело запроса одним файлом.
+// Файлы формы читаются в память: граф импорта все равно собирается в памяти целиком.
+func importFiles(r *http.Request) ([]importer.File, error) {
+	format := r.URL.Query().Get("format")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+	case "application/json":
+		return []importer.File{{Name: "body", Format: cmp.Or(format, importer.FormatJSON), Reader: r.Body}}, nil
+	case "text/csv":
+		return []importer.File{{Name: "body", Format: cmp.Or(format, importer.FormatCSV), Reader: r.Body}}, nil
+	default:
+		return []importer.File{{Name: "body", Format: format, Reader: r.Body}}, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", usecase.ErrInvalidImport, err)
+	}
+
+	var files []importer.File
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", usecase.ErrInvalidImport, err)
+		}
+
+		if part.FileName() == "" {
+			continue
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", usecase.ErrInvalidImport, part.FileName(), err)
+		}
+
+		files = append(files, importer.File{Name: part.FileName(), Format: format, Reader: bytes.NewReader(data)})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no files in multipart form", usecase.ErrInvalidImport)
+	}
+
+	return files, nil
+}
+
+func renderImportError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		renderError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	renderGraphError(w, err)
+}
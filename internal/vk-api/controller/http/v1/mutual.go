@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// userIDsParam VK id пользователей из параметра ids: через запятую или повторением параметра.
+func userIDsParam(r *http.Request) ([]uint64, error) {
+	var ids []uint64
+	for _, v := range r.URL.Query()["ids"] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+
+			id, err := strconv.ParseUint(s, Base, BitSize)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", usecase.ErrInvalidID, s)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// getMutual GET /mutual?ids=1,2
+func (ur *userRoutes) getMutual(w http.ResponseWriter, r *http.Request) {
+	ids, err := userIDsParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	mutual, err := ur.MutualConnections(r.Context(), ids)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, mutual)
+}
+
+func (ur *userRoutes) getMutualFollowers(w http.ResponseWriter, r *http.Request) {
+	ids, err := userIDsParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	followers, err := ur.MutualFollowers(r.Context(), ids)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, followers)
+}
+
+func (ur *userRoutes) getMutualSubscriptions(w http.ResponseWriter, r *http.Request) {
+	ids, err := userIDsParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	subscriptions, err := ur.MutualSubscriptions(r.Context(), ids)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, subscriptions)
+}
+
+func (ur *userRoutes) getCommonGroups(w http.ResponseWriter, r *http.Request) {
+	ids, err := userIDsParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	groups, err := ur.CommonGroups(r.Context(), ids)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, groups)
+}
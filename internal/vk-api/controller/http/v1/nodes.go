@@ -16,30 +16,97 @@ const (
 	BitSize = 64
 )
 
-func (ur *userRoutes) getAllNodes(w http.ResponseWriter, r *http.Request) {
-	nodes, err := ur.GetAllNodes(r.Context())
+// getNodes GET /nodes?label=&city=&sex=&name=&sort=&limit=&cursor=&properties=
+// sort - id или name, с префиксом - по убыванию.
+func (ur *userRoutes) getNodes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := models.NodeQuery{
+		Label:      params.Get("label"),
+		City:       params.Get("city"),
+		NamePrefix: params.Get("name"),
+	}
+
+	query.Sort, query.Desc = strings.CutPrefix(params.Get("sort"), "-")
+
+	if v := params.Get("sex"); v != "" {
+		sex, err := strconv.ParseUint(v, Base, 8)
+		if err != nil {
+			renderError(w, http.StatusBadRequest, fmt.Errorf("invalid sex: %q", v))
+			return
+		}
+
+		b := byte(sex)
+		query.Sex = &b
+	}
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			renderError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %q", v))
+			return
+		}
+
+		query.Limit = limit
+	}
+
+	if v := params.Get("properties"); v != "" {
+		withProperties, err := strconv.ParseBool(v)
+		if err != nil {
+			renderError(w, http.StatusBadRequest, fmt.Errorf("invalid properties: %q", v))
+			return
+		}
+
+		query.WithProperties = withProperties
+	}
+
+	page, err := ur.ListNodes(r.Context(), query, params.Get("cursor"))
 	if err != nil {
-		renderError(w, http.StatusInternalServerError, err)
+		renderGraphError(w, err)
 		return
 	}
 
-	renderJSON(w, nodes)
+	renderJSON(w, page)
 }
 
-func (ur *userRoutes) getNode(w http.ResponseWriter, r *http.Request) {
+// nodeTypes Сегмент пути /nodes/{type}/{id} и соответствующая метка узла.
+var nodeTypes = map[string]string{
+	"users":  models.LabelUser,
+	"groups": models.LabelGroup,
+}
+
+// nodeRef Ссылка на узел из пути: /nodes/{type}/{id} по VK id или /nodes/{elementID}.
+func nodeRef(r *http.Request) (models.NodeRef, error) {
+	if elementID := chi.URLParam(r, "elementID"); elementID != "" {
+		return models.NodeRef{ElementID: elementID}, nil
+	}
+
+	nodeType := chi.URLParam(r, "type")
+	label, ok := nodeTypes[nodeType]
+	if !ok {
+		return models.NodeRef{}, fmt.Errorf("invalid type of node: %s, use users or groups", nodeType)
+	}
+
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		renderError(w, http.StatusBadRequest, fmt.Errorf("empty node id"))
-		return
+		return models.NodeRef{}, fmt.Errorf("empty node id")
 	}
 
 	id, err := strconv.ParseUint(idStr, Base, BitSize)
+	if err != nil {
+		return models.NodeRef{}, err
+	}
+
+	return models.NodeRef{Label: label, ID: id}, nil
+}
+
+func (ur *userRoutes) getNode(w http.ResponseWriter, r *http.Request) {
+	ref, err := nodeRef(r)
 	if err != nil {
 		renderError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	node, err := ur.GetNodeWithRelationships(r.Context(), id)
+	node, err := ur.GetNodeWithRelationships(r.Context(), ref)
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, err)
 		return
@@ -60,51 +127,53 @@ func (ur *userRoutes) createNode(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			renderError(w, http.StatusBadRequest, err)
+			return
 		}
 
 		var user models.User
 		if err := json.Unmarshal(body, &user); err != nil {
 			renderError(w, http.StatusBadRequest, err)
+			return
 		}
 
+		// Пользователь со всеми подписчиками и подписками записывается в одной транзакции.
 		if err := ur.SaveUser(r.Context(), user); err != nil {
 			renderError(w, http.StatusInternalServerError, err)
+			return
 		}
 	case "group":
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			renderError(w, http.StatusBadRequest, err)
+			return
 		}
 
 		var group models.GroupWithSubscribers
 		if err := json.Unmarshal(body, &group); err != nil {
 			renderError(w, http.StatusBadRequest, err)
+			return
 		}
 
 		if err := ur.SaveGroup(r.Context(), group); err != nil {
 			renderError(w, http.StatusInternalServerError, err)
+			return
 		}
 	default:
 		renderError(w, http.StatusBadRequest, fmt.Errorf("empty or invalid type of node: %s, use user or group", nodeType))
+		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
 func (ur *userRoutes) deleteNode(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		renderError(w, http.StatusBadRequest, fmt.Errorf("empty node id"))
-		return
-	}
-
-	id, err := strconv.ParseUint(idStr, Base, BitSize)
+	ref, err := nodeRef(r)
 	if err != nil {
 		renderError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if err := ur.DeleteNode(r.Context(), id); err != nil {
+	if err := ur.DeleteNode(r.Context(), ref); err != nil {
 		renderError(w, http.StatusInternalServerError, err)
 		return
 	}
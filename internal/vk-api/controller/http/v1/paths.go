@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pathQuery Запрос путей из параметров.
+func pathQuery(params url.Values) (models.PathQuery, error) {
+	query := models.PathQuery{Types: typesParam(params), Direction: params.Get("direction")}
+
+	for key, id := range map[string]*uint64{"source_id": &query.SourceID, "target_id": &query.TargetID} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(value, Base, BitSize)
+		if err != nil {
+			return models.PathQuery{}, fmt.Errorf("%w: invalid %s %q", usecase.ErrInvalidQuery, key, value)
+		}
+		*id = n
+	}
+
+	for key, n := range map[string]*int{"max_hops": &query.MaxHops, "limit": &query.Limit} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+
+		i, err := strconv.Atoi(value)
+		if err != nil || i <= 0 {
+			return models.PathQuery{}, fmt.Errorf("%w: invalid %s %q", usecase.ErrInvalidQuery, key, value)
+		}
+		*n = i
+	}
+
+	for key, b := range map[string]*bool{"via_groups": &query.ViaGroups, "all": &query.All} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return models.PathQuery{}, fmt.Errorf("%w: invalid %s %q", usecase.ErrInvalidQuery, key, value)
+		}
+		*b = v
+	}
+
+	return query, nil
+}
+
+// typesParam Типы связей из параметра types: через запятую или повторением параметра.
+func typesParam(params url.Values) []string {
+	var types []string
+	for _, v := range params["types"] {
+		for _, relType := range strings.Split(v, ",") {
+			if relType = strings.TrimSpace(relType); relType != "" {
+				types = append(types, relType)
+			}
+		}
+	}
+
+	return types
+}
+
+// getPaths GET /paths?source_id=&target_id=&types=&direction=&via_groups=&max_hops=&all=&limit=
+func (ur *userRoutes) getPaths(w http.ResponseWriter, r *http.Request) {
+	query, err := pathQuery(r.URL.Query())
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	paths, err := ur.FindPaths(r.Context(), query)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, paths)
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// relationshipQuery Связь или фильтр из параметров type, source_id, target_id и target_label.
+func relationshipQuery(query url.Values) (models.Relationship, error) {
+	rel := models.Relationship{
+		Type:        query.Get("type"),
+		TargetLabel: query.Get("target_label"),
+	}
+
+	for key, id := range map[string]*uint64{"source_id": &rel.SourceID, "target_id": &rel.TargetID} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(value, Base, BitSize)
+		if err != nil {
+			return models.Relationship{}, fmt.Errorf("invalid %s: %q", key, value)
+		}
+		*id = n
+	}
+
+	return rel, nil
+}
+
+func (ur *userRoutes) getRelationships(w http.ResponseWriter, r *http.Request) {
+	rel, err := relationshipQuery(r.URL.Query())
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	relationships, err := ur.ListRelationships(r.Context(), models.RelationshipFilter(rel))
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, relationships)
+}
+
+func (ur *userRoutes) createRelationship(w http.ResponseWriter, r *http.Request) {
+	var rel models.Relationship
+	if err := json.NewDecoder(r.Body).Decode(&rel); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if rel.TargetLabel == "" {
+		rel.TargetLabel = models.LabelUser
+	}
+
+	if err := ur.CreateRelationship(r.Context(), rel); err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSONStatus(w, http.StatusCreated, rel)
+}
+
+// deleteRelationship DELETE /relationships?type=&source_id=&target_id=&target_label=, по умолчанию target_label=User.
+func (ur *userRoutes) deleteRelationship(w http.ResponseWriter, r *http.Request) {
+	rel, err := relationshipQuery(r.URL.Query())
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if rel.TargetLabel == "" {
+		rel.TargetLabel = models.LabelUser
+	}
+
+	if err := ur.DeleteRelationship(r.Context(), rel); err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
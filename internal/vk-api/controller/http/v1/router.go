@@ -17,10 +17,21 @@ func NewRouter(cfg *config.Config, r *chi.Mux, uc *usecase.UserUsecase) {
 }
 
 func renderJSON(w http.ResponseWriter, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+	renderJSONStatus(w, http.StatusOK, v)
+}
+
+// renderJSONStatus Ответ v со статусом statusCode. v кодируется до записи заголовка, чтобы ошибка
+// кодирования вернулась статусом 500.
+func renderJSONStatus(w http.ResponseWriter, statusCode int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
 		renderError(w, http.StatusInternalServerError, err)
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(append(body, '\n'))
 }
 
 type jsonError struct {
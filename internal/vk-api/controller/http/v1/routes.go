@@ -12,11 +12,65 @@ type userRoutes struct {
 func newUserRoutes(r chi.Router, uc *usecase.UserUsecase) {
 	ur := &userRoutes{uc}
 
-	r.Get("/nodes", ur.getAllNodes)
-	r.Get("/nodes/{id}", ur.getNode)
+	r.Get("/nodes", ur.getNodes)
+	r.Get("/nodes/{elementID}", ur.getNode)
+	r.Get("/nodes/{type}/{id}", ur.getNode)
+	r.Get("/nodes/{elementID}/ego", ur.getEgoNetwork)
+	r.Get("/nodes/{type}/{id}/ego", ur.getEgoNetwork)
+
+	r.Get("/users", ur.getUsers)
+	r.Get("/users/{id}", ur.getUserByID)
+	r.Get("/users/{id}/followers", ur.getUserFollowers)
+	r.Get("/users/{id}/subscriptions", ur.getUserSubscriptions)
+
+	r.Get("/groups", ur.getGroups)
+	r.Get("/groups/{id}", ur.getGroupByID)
+	r.Get("/groups/{id}/subscribers", ur.getGroupSubscribers)
+
+	r.Get("/relationships", ur.getRelationships)
+	r.Get("/paths", ur.getPaths)
+
+	r.Get("/mutual", ur.getMutual)
+	r.Get("/mutual/followers", ur.getMutualFollowers)
+	r.Get("/mutual/subscriptions", ur.getMutualSubscriptions)
+	r.Get("/mutual/groups", ur.getCommonGroups)
+
+	r.Get("/stats/counts", ur.getCounts)
+	r.Get("/stats/top-users", ur.getTopUsers)
+	r.Get("/stats/top-groups", ur.getTopGroups)
+	r.Get("/stats/users-with-different-groups", ur.getUsersWithDifferentGroups)
+
+	// EventSource не передает заголовки, поэтому поток событий принимает токен и в ?access_token=.
+	r.With(accessTokenQueryMiddleware, userHasAnyRoleMiddleware("editor")).Get("/crawls/{id}/events", ur.streamCrawlEvents)
 
 	r.With(userHasAnyRoleMiddleware("editor")).Group(func(r chi.Router) {
 		r.Post("/nodes", ur.createNode)
-		r.Delete("/nodes/{id}", ur.deleteNode)
+		r.Delete("/nodes/{elementID}", ur.deleteNode)
+		r.Delete("/nodes/{type}/{id}", ur.deleteNode)
+
+		r.Post("/users", ur.createUser)
+		r.Put("/users/{id}", ur.replaceUser)
+		r.Patch("/users/{id}", ur.patchUser)
+		r.Delete("/users/{id}", ur.deleteUser)
+
+		r.Post("/groups", ur.createGroup)
+		r.Put("/groups/{id}", ur.replaceGroup)
+		r.Patch("/groups/{id}", ur.patchGroup)
+		r.Delete("/groups/{id}", ur.deleteGroup)
+
+		r.Post("/relationships", ur.createRelationship)
+		r.Delete("/relationships", ur.deleteRelationship)
+
+		r.Get("/export", ur.getExport)
+		r.Post("/import", ur.postImport)
+
+		r.Get("/vk/tokens", ur.getTokensStatus)
+
+		r.Post("/crawls", ur.createCrawl)
+		r.Get("/crawls", ur.getCrawls)
+		r.Get("/crawls/{id}", ur.getCrawl)
+		r.Post("/crawls/{id}/pause", ur.pauseCrawl)
+		r.Post("/crawls/{id}/resume", ur.resumeCrawl)
+		r.Delete("/crawls/{id}", ur.cancelCrawl)
 	})
 }
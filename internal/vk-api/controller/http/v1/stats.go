@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"net/http"
+	"strconv"
+)
+
+// limitParam Параметр limit, по умолчанию defaultLimit. Диапазон проверяет usecase.
+func limitParam(r *http.Request, defaultLimit int) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid limit %q", usecase.ErrInvalidQuery, v)
+	}
+
+	return limit, nil
+}
+
+func (ur *userRoutes) getCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := ur.GetCounts(r.Context())
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, counts)
+}
+
+func (ur *userRoutes) getTopUsers(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r, usecase.DefaultStatsLimit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	users, err := ur.GetTopUsersByFollowersCount(r.Context(), limit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, users)
+}
+
+func (ur *userRoutes) getTopGroups(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r, usecase.DefaultStatsLimit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	groups, err := ur.GetTopGroupsBySubscribersCount(r.Context(), limit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, groups)
+}
+
+func (ur *userRoutes) getUsersWithDifferentGroups(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r, usecase.DefaultStatsLimit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	users, err := ur.GetUsersWithDifferentGroups(r.Context(), limit)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, users)
+}
@@ -0,0 +1,9 @@
+package v1
+
+import (
+	"net/http"
+)
+
+func (ur *userRoutes) getTokensStatus(w http.ResponseWriter, r *http.Request) {
+	renderJSON(w, ur.GetTokensStatus())
+}
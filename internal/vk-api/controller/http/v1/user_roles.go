@@ -30,6 +30,7 @@ func userHasAnyRoleMiddleware(roles ...string) func(http.Handler) http.Handler {
 			for _, role := range roles {
 				if _, ok := rolesMap[role]; ok {
 					next.ServeHTTP(w, r)
+					return
 				}
 			}
 
@@ -38,6 +39,18 @@ func userHasAnyRoleMiddleware(roles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// accessTokenQueryMiddleware Берет токен из параметра access_token, если нет заголовка Authorization.
+// Нужен для EventSource в браузере: он не умеет передавать заголовки. Ставится перед userHasAnyRoleMiddleware.
+func accessTokenQueryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func getRolesFromToken(tokenString string) ([]string, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
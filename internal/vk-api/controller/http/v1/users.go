@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/go-chi/chi"
+	"net/http"
+	"strconv"
+)
+
+// vkIDParam VK id пользователя или группы из {id} пути.
+func vkIDParam(r *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), Base, BitSize)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: %q", usecase.ErrInvalidID, chi.URLParam(r, "id"))
+	}
+
+	return id, nil
+}
+
+func (ur *userRoutes) getUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := ur.ListUsers(r.Context())
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, users)
+}
+
+func (ur *userRoutes) getUserByID(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	user, err := ur.FindUser(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, user)
+}
+
+func (ur *userRoutes) createUser(w http.ResponseWriter, r *http.Request) {
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Вложенные подписчики и подписки записываются в той же транзакции, что и пользователь.
+	created, err := ur.CreateUser(r.Context(), user)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/users/"+strconv.FormatUint(created.ID, Base))
+	renderJSONStatus(w, http.StatusCreated, created)
+}
+
+// replaceUser PUT: свойства пользователя заменяются телом запроса целиком.
+func (ur *userRoutes) replaceUser(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ur.updateUser(w, r, id, user)
+}
+
+// patchUser PATCH: меняются только переданные в теле свойства.
+func (ur *userRoutes) patchUser(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	user, err := ur.FindUser(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ur.updateUser(w, r, id, user)
+}
+
+func (ur *userRoutes) updateUser(w http.ResponseWriter, r *http.Request, id uint64, user models.User) {
+	if user.ID != 0 && user.ID != id {
+		renderGraphError(w, fmt.Errorf("%w: body id %d does not match path id %d", usecase.ErrInvalidID, user.ID, id))
+		return
+	}
+	user.ID = id
+
+	updated, err := ur.UpdateUser(r.Context(), user)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, updated)
+}
+
+func (ur *userRoutes) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	if err := ur.DeleteUser(r.Context(), id); err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ur *userRoutes) getUserFollowers(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	followers, err := ur.ListFollowers(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, followers)
+}
+
+func (ur *userRoutes) getUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	id, err := vkIDParam(r)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	subscriptions, err := ur.ListSubscriptions(r.Context(), id)
+	if err != nil {
+		renderGraphError(w, err)
+		return
+	}
+
+	renderJSON(w, subscriptions)
+}
+
+func renderGraphError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound), errors.Is(err, usecase.ErrGroupNotFound),
+		errors.Is(err, usecase.ErrRelationshipNotFound), errors.Is(err, usecase.ErrNodeNotFound):
+		renderError(w, http.StatusNotFound, err)
+	case errors.Is(err, usecase.ErrInvalidID), errors.Is(err, usecase.ErrInvalidRelationship),
+		errors.Is(err, usecase.ErrInvalidQuery), errors.Is(err, usecase.ErrInvalidImport):
+		renderError(w, http.StatusBadRequest, err)
+	default:
+		renderError(w, http.StatusInternalServerError, err)
+	}
+}
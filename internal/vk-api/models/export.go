@@ -0,0 +1,10 @@
+package models
+
+// ExportFilter Подграф для выгрузки: узлы по фильтрам и связи Types между ними. Пустой фильтр - весь граф.
+// Фильтры City и Sex, как в NodeQuery, оставляют только пользователей.
+type ExportFilter struct {
+	Label string
+	City  string
+	Sex   *byte
+	Types []string
+}
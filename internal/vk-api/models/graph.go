@@ -0,0 +1,12 @@
+package models
+
+// Graph Узлы и связи для пакетной записи в хранилище.
+type Graph struct {
+	Users         []User         `json:"users"`
+	Groups        []Group        `json:"groups"`
+	Relationships []Relationship `json:"relationships"`
+	// StubUsers и StubGroups VK id узлов, известных только по связям. Такие узлы создаются без свойств,
+	// если их еще нет, свойства существующих не меняются.
+	StubUsers  []uint64 `json:"stub_users,omitempty"`
+	StubGroups []uint64 `json:"stub_groups,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// ImportReport Итог импорта. При DryRun хранилище не меняется, а числа показывают, что было бы записано.
+// Если запись прервалась, Error содержит причину, а Written - то, что уже сохранено.
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	Files  int  `json:"files"`
+	// Users, Groups и Relationships Узлы и связи без повторов.
+	Users         int `json:"users"`
+	Groups        int `json:"groups"`
+	Relationships int `json:"relationships"`
+	// Stubs Узлы, известные только по связям из CSV.
+	Stubs int `json:"stubs"`
+	// Duplicates Повторно встреченные узлы с тем же VK id и повторные связи. Непустые свойства более поздней
+	// записи узла заменяют прежние.
+	Duplicates int `json:"duplicates"`
+	// Invalid Пропущенные записи. Вместе с записью пользователя пропускаются вложенные в нее подписчики и подписки.
+	Invalid int `json:"invalid"`
+	// Errors Причины пропуска первых записей.
+	Errors []string `json:"errors,omitempty"`
+	// Written Записанные в хранилище узлы и связи.
+	Written ImportProgress `json:"written"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// ImportProgress Число записанных узлов и связей каждого вида.
+type ImportProgress struct {
+	Users         int `json:"users"`
+	Groups        int `json:"groups"`
+	Stubs         int `json:"stubs"`
+	Relationships int `json:"relationships"`
+}
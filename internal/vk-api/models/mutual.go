@@ -0,0 +1,26 @@
+package models
+
+// UserOverlap Пользователи, общие для всех пользователей набора. Union - число связанных хотя бы с одним,
+// Jaccard - Count / Union или 0, если Union 0.
+type UserOverlap struct {
+	Count   int     `json:"count"`
+	Union   int     `json:"union"`
+	Jaccard float64 `json:"jaccard"`
+	Users   []User  `json:"users"`
+}
+
+// GroupOverlap Группы, общие для всех пользователей набора. Поля как у UserOverlap.
+type GroupOverlap struct {
+	Count   int     `json:"count"`
+	Union   int     `json:"union"`
+	Jaccard float64 `json:"jaccard"`
+	Groups  []Group `json:"groups"`
+}
+
+// MutualConnections Общие подписчики, общие подписки на пользователей и общие группы набора пользователей.
+type MutualConnections struct {
+	UserIDs       []uint64     `json:"user_ids"`
+	Followers     UserOverlap  `json:"followers"`
+	Subscriptions UserOverlap  `json:"subscriptions"`
+	Groups        GroupOverlap `json:"groups"`
+}
@@ -1,6 +1,52 @@
 package models
 
+const (
+	NodeSortID   = "id"
+	NodeSortName = "name"
+)
+
+// Node Узел графа. ID - VK id пользователя или группы, ElementID - elementId узла в хранилище.
+// Properties заполняется только по запросу, ключи совпадают со свойствами узла в хранилище.
 type Node struct {
-	ID     int64    `json:"id"`
-	Labels []string `json:"labels"`
+	ID         uint64                 `json:"id"`
+	Label      string                 `json:"label"`
+	ElementID  string                 `json:"element_id"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// NodeRef Ссылка на узел: по метке и VK id или, если задан, по ElementID.
+type NodeRef struct {
+	Label     string
+	ID        uint64
+	ElementID string
+}
+
+// NodeQuery Выборка узлов. Пустые фильтры не ограничивают выборку, фильтры City и Sex оставляют только пользователей.
+// Узлы упорядочены по Sort, затем по метке и VK id.
+type NodeQuery struct {
+	Label string
+	City  string
+	Sex   *byte
+	// NamePrefix Начало имени пользователя или названия группы без учета регистра.
+	NamePrefix     string
+	Sort           string
+	Desc           bool
+	Limit          int
+	After          *NodeCursor
+	WithProperties bool
+}
+
+// NodeCursor Позиция последнего узла страницы. Name заполняется при сортировке по имени.
+type NodeCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Name  string `json:"n,omitempty"`
+	Label string `json:"l"`
+	ID    uint64 `json:"i"`
+}
+
+// NodePage Страница узлов. NextCursor пустой на последней странице.
+type NodePage struct {
+	Nodes      []Node `json:"nodes"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
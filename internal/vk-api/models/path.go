@@ -0,0 +1,59 @@
+package models
+
+const (
+	DirectionOut  = "out"
+	DirectionIn   = "in"
+	DirectionBoth = "both"
+)
+
+// PathQuery Поиск кратчайших путей от пользователя SourceID к пользователю TargetID.
+type PathQuery struct {
+	SourceID uint64
+	TargetID uint64
+	// Types Типы связей, по которым проходит путь.
+	Types []string
+	// Direction Обход связей: out - по их направлению, in - против, both - в любую сторону.
+	Direction string
+	// ViaGroups Разрешает промежуточные узлы-группы. Через группу путь проходит по двум связям Subscribe
+	// навстречу друг другу, поэтому это имеет смысл только с Direction both.
+	ViaGroups bool
+	MaxHops   int
+	// All Все кратчайшие пути, но не больше Limit. Иначе один путь.
+	All   bool
+	Limit int
+}
+
+// Path Узлы пути по порядку и связи между соседними узлами. Связи сохраняют собственное направление,
+// которое при обходе in или both может не совпадать с направлением пути.
+type Path struct {
+	Length        int            `json:"length"`
+	Nodes         []Node         `json:"nodes"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Paths Кратчайшие пути между двумя пользователями. Length - степень разделения, 0 - если пути нет.
+type Paths struct {
+	SourceID uint64 `json:"source_id"`
+	TargetID uint64 `json:"target_id"`
+	Length   int    `json:"length"`
+	Paths    []Path `json:"paths"`
+}
+
+// EgoQuery Окрестность узла Center радиусом Hops связей.
+type EgoQuery struct {
+	Center NodeRef
+	Hops   int
+	// Types Типы связей, по которым идет обход и которые попадают в подграф.
+	Types []string
+	// Direction Обход связей как в PathQuery.
+	Direction string
+	// Limit Наибольшее число узлов вместе с Center. Узлы берутся по удаленности от центра, затем по метке и VK id.
+	Limit int
+}
+
+// Subgraph Узлы и все связи между ними. Truncated - часть окрестности не вошла из-за лимита узлов.
+type Subgraph struct {
+	Nodes         []Node         `json:"nodes"`
+	Relationships []Relationship `json:"relationships"`
+	Truncated     bool           `json:"truncated"`
+}
@@ -0,0 +1,26 @@
+package models
+
+const (
+	LabelUser  = "User"
+	LabelGroup = "Group"
+
+	RelationshipFollow    = "Follow"
+	RelationshipSubscribe = "Subscribe"
+)
+
+// Relationship Связь графа. Источник всегда пользователь: Follow ведет от подписчика к пользователю,
+// Subscribe - от пользователя к пользователю или группе, на которых он подписан.
+type Relationship struct {
+	Type        string `json:"type"`
+	SourceID    uint64 `json:"source_id"`
+	TargetID    uint64 `json:"target_id"`
+	TargetLabel string `json:"target_label"`
+}
+
+// RelationshipFilter Отбор связей. Пустые поля не ограничивают выборку.
+type RelationshipFilter struct {
+	Type        string
+	SourceID    uint64
+	TargetID    uint64
+	TargetLabel string
+}
@@ -0,0 +1,19 @@
+package models
+
+// Counts Число узлов в графе.
+type Counts struct {
+	Users  int `json:"users"`
+	Groups int `json:"groups"`
+}
+
+// TopUser Пользователь и число его подписчиков.
+type TopUser struct {
+	User
+	FollowersCount int `json:"followers_count"`
+}
+
+// TopGroup Группа и число ее подписчиков-пользователей.
+type TopGroup struct {
+	Group
+	SubscribersCount int `json:"subscribers_count"`
+}
@@ -0,0 +1,533 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
+	"github.com/rs/zerolog/log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultWorkers   = 4
+	DefaultBatchSize = 12
+)
+
+type VKClient interface {
+	GetUsersWithConnections(ctx context.Context, ids ...uint64) ([]rest.UserConnections, error)
+}
+
+type Repository interface {
+	CreateUser(ctx context.Context, user models.User) error
+	CreateGroup(ctx context.Context, group models.Group) error
+	CreateFollowRelationship(ctx context.Context, follower models.User, followee models.User) error
+	CreateSubscribeUserUserRelationship(ctx context.Context, subscriber models.User, subscribed models.User) error
+	CreateSubscribeUserGroupRelationship(ctx context.Context, user models.User, group models.Group) error
+}
+
+type Config struct {
+	// Depth Число уровней обхода: 1 - только стартовые пользователи со своими связями.
+	Depth int `json:"depth"`
+	// Workers Число одновременных запросов к VK.
+	Workers int `json:"workers"`
+	// BatchSize Число пользователей, запрашиваемых одним вызовом клиента.
+	BatchSize int `json:"batch_size"`
+	// FanOut Ограничение числа новых соседей одного пользователя, добавляемых в следующий уровень,
+	// по уровням. Последнее значение действует для всех более глубоких уровней, 0 - без ограничения.
+	FanOut []int `json:"fan_out,omitempty"`
+	// MaxUsers Ограничение общего числа обойденных пользователей, 0 - без ограничения.
+	MaxUsers int `json:"max_users,omitempty"`
+}
+
+func (cfg Config) fanOut(level int) int {
+	if len(cfg.FanOut) == 0 {
+		return 0
+	}
+
+	return cfg.FanOut[min(level, len(cfg.FanOut)-1)]
+}
+
+// Stats Счетчики обхода.
+type Stats struct {
+	Visited uint64 `json:"visited"`
+	Users   uint64 `json:"users"`
+	Groups  uint64 `json:"groups"`
+	Edges   uint64 `json:"edges"`
+	Errors  uint64 `json:"errors"`
+}
+
+type counters struct {
+	visited, users, groups, edges, errors atomic.Uint64
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Visited: c.visited.Load(),
+		Users:   c.users.Load(),
+		Groups:  c.groups.Load(),
+		Edges:   c.edges.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
+
+// State Состояние обхода, достаточное для его продолжения после перезапуска. Позиция обхода - пользователь:
+// он считается обработанным, когда записаны все его связи. Смещения постраничной загрузки подписчиков
+// и подписок не сохраняются, прерванный пользователь при продолжении запрашивается заново с первой страницы.
+type State struct {
+	// Level Текущий уровень обхода.
+	Level int `json:"level"`
+	// Frontier Еще не обработанные пользователи текущего уровня.
+	Frontier []uint64 `json:"frontier"`
+	// Next Пользователи, найденные для следующего уровня.
+	Next    []uint64 `json:"next"`
+	Visited []uint64 `json:"visited"`
+	Stats   Stats    `json:"stats"`
+}
+
+// Crawler Обход графа VK в ширину пулом воркеров. Каждый пользователь запрашивается не более
+// одного раза, найденные узлы и связи сразу записываются в репозиторий.
+type Crawler struct {
+	client VKClient
+	repo   Repository
+	cfg    Config
+
+	mu       sync.Mutex
+	visited  map[uint64]struct{}
+	written  map[string]struct{}
+	level    int
+	frontier []uint64
+	pending  map[uint64]struct{}
+	next     []uint64
+
+	counters counters
+
+	// onProgress Вызывается после каждой обработанной пачки и завершения уровня.
+	onProgress func()
+	// onEvent Получает события обхода. Вызывается из воркеров, должен быть быстрым.
+	onEvent func(Event)
+}
+
+func New(client VKClient, repo Repository, cfg Config) *Crawler {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+
+	return &Crawler{
+		client:  client,
+		repo:    repo,
+		cfg:     cfg,
+		visited: make(map[uint64]struct{}),
+		written: make(map[string]struct{}),
+		pending: make(map[uint64]struct{}),
+	}
+}
+
+// OnProgress Устанавливает обработчик прогресса обхода. Вызывается из воркеров, должен быть быстрым.
+func (c *Crawler) OnProgress(fn func()) {
+	c.onProgress = fn
+}
+
+// OnEvent Устанавливает обработчик событий обхода.
+func (c *Crawler) OnEvent(fn func(Event)) {
+	c.onEvent = fn
+}
+
+func (c *Crawler) emit(e Event) {
+	if c.onEvent == nil {
+		return
+	}
+
+	e.Time = time.Now().UTC()
+	c.onEvent(e)
+}
+
+func (c *Crawler) Stats() Stats {
+	return c.counters.stats()
+}
+
+// State Снимок состояния обхода.
+func (c *Crawler) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := State{
+		Level: c.level,
+		Next:  slices.Clone(c.next),
+		Stats: c.counters.stats(),
+	}
+
+	for _, id := range c.frontier {
+		if _, ok := c.pending[id]; ok {
+			state.Frontier = append(state.Frontier, id)
+		}
+	}
+
+	state.Visited = make([]uint64, 0, len(c.visited))
+	for id := range c.visited {
+		state.Visited = append(state.Visited, id)
+	}
+	slices.Sort(state.Visited)
+
+	return state
+}
+
+// Progress Текущий уровень, число необработанных пользователей уровня, размер следующего уровня и счетчики.
+func (c *Crawler) Progress() (level, frontier, next int, stats Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.level, len(c.pending), len(c.next), c.counters.stats()
+}
+
+// Crawl Обходит граф от seeds на cfg.Depth уровней.
+func (c *Crawler) Crawl(ctx context.Context, seeds []uint64) (Stats, error) {
+	c.setLevel(0, c.markVisited(seeds, 0, 0), nil)
+	return c.run(ctx)
+}
+
+// Resume Продолжает обход с сохраненного состояния. Пользователи, обработка которых была прервана,
+// запрашиваются повторно целиком, с первой страницы связей: запись в репозиторий идемпотентна.
+func (c *Crawler) Resume(ctx context.Context, state State) (Stats, error) {
+	c.mu.Lock()
+	for _, id := range state.Visited {
+		c.visited[id] = struct{}{}
+	}
+	c.mu.Unlock()
+
+	c.counters.visited.Store(state.Stats.Visited)
+	c.counters.users.Store(state.Stats.Users)
+	c.counters.groups.Store(state.Stats.Groups)
+	c.counters.edges.Store(state.Stats.Edges)
+	c.counters.errors.Store(state.Stats.Errors)
+
+	c.setLevel(state.Level, state.Frontier, state.Next)
+	return c.run(ctx)
+}
+
+func (c *Crawler) run(ctx context.Context) (Stats, error) {
+	ctx = rest.WithWaitObserver(ctx, func(method string, waited time.Duration) {
+		c.mu.Lock()
+		level := c.level
+		c.mu.Unlock()
+
+		c.emit(Event{Type: EventRateLimitWait, Level: level, Method: method, WaitMS: waited.Milliseconds()})
+	})
+
+	for {
+		c.mu.Lock()
+		level, frontier := c.level, slices.Clone(c.frontier)
+		c.mu.Unlock()
+
+		if level >= c.cfg.Depth {
+			break
+		}
+
+		// Пустой frontier при непустом next - состояние, сохраненное после последней пачки уровня,
+		// но до перехода на следующий: уровень уже обойден, остается только перейти.
+		if len(frontier) > 0 {
+			if err := c.crawlLevel(ctx, level, frontier); err != nil {
+				return c.Stats(), err
+			}
+		}
+
+		c.mu.Lock()
+		next := c.next
+		c.mu.Unlock()
+
+		if len(frontier) == 0 && len(next) == 0 {
+			break
+		}
+
+		log.Info().Msgf("Обошел уровень %d: %d пользователей, следующий уровень: %d", level, len(frontier), len(next))
+		stats := c.Stats()
+		c.emit(Event{Type: EventLevelDone, Level: level, Count: len(next), Stats: &stats})
+		c.setLevel(level+1, next, nil)
+		c.progress()
+	}
+
+	return c.Stats(), nil
+}
+
+func (c *Crawler) setLevel(level int, frontier, next []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.level = level
+	c.frontier = frontier
+	c.next = next
+	c.pending = make(map[uint64]struct{}, len(frontier))
+	for _, id := range frontier {
+		c.pending[id] = struct{}{}
+	}
+}
+
+func (c *Crawler) progress() {
+	if c.onProgress != nil {
+		c.onProgress()
+	}
+}
+
+// crawlLevel Обходит один уровень, собирая непосещенных соседей для следующего.
+func (c *Crawler) crawlLevel(ctx context.Context, level int, frontier []uint64) error {
+	batches := make(chan []uint64)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	expand := level+1 < c.cfg.Depth
+
+	for i := 0; i < c.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				found, err := c.crawlBatch(ctx, level, batch, expand)
+
+				// Найденные соседи уже отмечены посещенными, поэтому сохраняются даже при ошибке,
+				// а пачка остается в frontier и будет обработана повторно при продолжении.
+				c.mu.Lock()
+				c.next = append(c.next, found...)
+				if err == nil {
+					for _, id := range batch {
+						delete(c.pending, id)
+					}
+				}
+				c.mu.Unlock()
+
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					continue
+				}
+
+				c.progress()
+			}
+		}()
+	}
+
+	for batch := range slices.Chunk(frontier, c.cfg.BatchSize) {
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return firstErr
+}
+
+func (c *Crawler) crawlBatch(ctx context.Context, level int, ids []uint64, expand bool) ([]uint64, error) {
+	conns, err := c.client.GetUsersWithConnections(ctx, ids...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Ошибка одной пачки не должна останавливать весь обход.
+		c.counters.errors.Add(1)
+		log.Error().Err(err).Msgf("c.client.GetUsersWithConnections: %v", ids)
+		c.emitError(level, 0, err)
+		return nil, nil
+	}
+
+	var next []uint64
+	for _, conn := range conns {
+		found, err := c.processUser(ctx, level, conn, expand)
+		if err != nil {
+			return next, err
+		}
+
+		next = append(next, found...)
+	}
+
+	return next, nil
+}
+
+func (c *Crawler) processUser(ctx context.Context, level int, conn rest.UserConnections, expand bool) ([]uint64, error) {
+	user := conn.User
+	c.counters.visited.Add(1)
+
+	for _, err := range []error{conn.FollowersErr, conn.SubscriptionsErr} {
+		if err != nil {
+			c.counters.errors.Add(1)
+			log.Warn().Err(err).Msgf("Не удалось получить связи пользователя %d", user.ID)
+			c.emitError(level, user.ID, err)
+		}
+	}
+
+	if err := c.writeUser(ctx, user, true); err != nil {
+		return nil, err
+	}
+
+	var (
+		neighbours []uint64
+		edges      int
+	)
+
+	for _, follower := range user.Followers {
+		if err := c.writeUser(ctx, follower, false); err != nil {
+			return nil, err
+		}
+
+		if c.firstWrite(edgeKey(models.RelationshipFollow, follower.ID, models.LabelUser, user.ID)) {
+			if err := c.repo.CreateFollowRelationship(ctx, follower, user); err != nil {
+				return nil, fmt.Errorf("c.repo.CreateFollowRelationship: %w", err)
+			}
+			c.counters.edges.Add(1)
+			edges++
+		}
+
+		neighbours = append(neighbours, follower.ID)
+	}
+
+	for _, subscription := range user.Subscriptions.Users {
+		if err := c.writeUser(ctx, subscription, false); err != nil {
+			return nil, err
+		}
+
+		if c.firstWrite(edgeKey(models.RelationshipSubscribe, user.ID, models.LabelUser, subscription.ID)) {
+			if err := c.repo.CreateSubscribeUserUserRelationship(ctx, user, subscription); err != nil {
+				return nil, fmt.Errorf("c.repo.CreateSubscribeUserUserRelationship: %w", err)
+			}
+			c.counters.edges.Add(1)
+			edges++
+		}
+
+		neighbours = append(neighbours, subscription.ID)
+	}
+
+	for _, group := range user.Subscriptions.Groups {
+		if err := c.writeGroup(ctx, group); err != nil {
+			return nil, err
+		}
+
+		if c.firstWrite(edgeKey(models.RelationshipSubscribe, user.ID, models.LabelGroup, group.ID)) {
+			if err := c.repo.CreateSubscribeUserGroupRelationship(ctx, user, group); err != nil {
+				return nil, fmt.Errorf("c.repo.CreateSubscribeUserGroupRelationship: %w", err)
+			}
+			c.counters.edges.Add(1)
+			edges++
+		}
+	}
+
+	name := user.FirstName + " " + user.LastName
+
+	log.Info().Msgf("Обошел пользователя: %s", name)
+	c.emit(Event{Type: EventUserVisited, Level: level, UserID: user.ID, Name: name})
+	if edges > 0 {
+		c.emit(Event{Type: EventEdgesWritten, Level: level, UserID: user.ID, Count: edges})
+	}
+
+	if !expand {
+		return nil, nil
+	}
+
+	return c.markVisited(neighbours, c.cfg.MaxUsers, c.cfg.fanOut(level)), nil
+}
+
+func (c *Crawler) emitError(level int, userID uint64, err error) {
+	e := Event{Type: EventVKError, Level: level, UserID: userID, Error: err.Error()}
+	if vkErr, ok := rest.AsVKError(err); ok {
+		e.Method = vkErr.Method
+	}
+
+	c.emit(e)
+}
+
+// markVisited Отмечает пользователей посещенными и возвращает тех, кто не был посещен ранее.
+// При maxUsers > 0 общее число посещенных не превышает maxUsers, при fanOut > 0 возвращается
+// не больше fanOut новых пользователей: уже посещенные в ограничение не входят.
+func (c *Crawler) markVisited(ids []uint64, maxUsers, fanOut int) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var fresh []uint64
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+
+		if maxUsers > 0 && len(c.visited) >= maxUsers || fanOut > 0 && len(fresh) >= fanOut {
+			break
+		}
+
+		if _, ok := c.visited[id]; ok {
+			continue
+		}
+
+		c.visited[id] = struct{}{}
+		fresh = append(fresh, id)
+	}
+
+	return fresh
+}
+
+// edgeKey Ключ связи для firstWrite.
+func edgeKey(relType string, sourceID uint64, targetLabel string, targetID uint64) string {
+	return fmt.Sprintf("%s:%d->%s:%d", relType, sourceID, targetLabel, targetID)
+}
+
+// firstWrite Возвращает true, если узел или связь с таким ключом еще не записывались этим обходом.
+func (c *Crawler) firstWrite(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.written[key]; ok {
+		return false
+	}
+
+	c.written[key] = struct{}{}
+	return true
+}
+
+// writeUser Записывает пользователя. Соседи записываются один раз, а обойденный пользователь
+// (force) перезаписывается полными данными из users.get.
+func (c *Crawler) writeUser(ctx context.Context, user models.User, force bool) error {
+	first := c.firstWrite(fmt.Sprintf("%s:%d", models.LabelUser, user.ID))
+	if !first && !force {
+		return nil
+	}
+
+	if err := c.repo.CreateUser(ctx, user); err != nil {
+		return fmt.Errorf("c.repo.CreateUser: %w", err)
+	}
+
+	if first {
+		c.counters.users.Add(1)
+	}
+
+	return nil
+}
+
+func (c *Crawler) writeGroup(ctx context.Context, group models.Group) error {
+	if !c.firstWrite(fmt.Sprintf("%s:%d", models.LabelGroup, group.ID)) {
+		return nil
+	}
+
+	if err := c.repo.CreateGroup(ctx, group); err != nil {
+		return fmt.Errorf("c.repo.CreateGroup: %w", err)
+	}
+	c.counters.groups.Add(1)
+
+	return nil
+}
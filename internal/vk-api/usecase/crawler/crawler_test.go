@@ -0,0 +1,249 @@
+package crawler
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest/vkfake"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// fakeClient Граф: 1 <- 2, 1 <- 3, 2 <- 3, 3 <- 4, 1 подписан на группу 100.
+type fakeClient struct {
+	mu        sync.Mutex
+	requested map[uint64]int
+	followers map[uint64][]uint64
+
+	// blockOnce Если задан, первый запрос сообщает о себе в канал и ждет отмены контекста.
+	blockOnce chan struct{}
+	blocked   bool
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		requested: make(map[uint64]int),
+		followers: map[uint64][]uint64{
+			1: {2, 3},
+			2: {3},
+			3: {4},
+		},
+	}
+}
+
+func (f *fakeClient) GetUsersWithConnections(ctx context.Context, ids ...uint64) ([]rest.UserConnections, error) {
+	f.mu.Lock()
+	block := f.blockOnce != nil && !f.blocked
+	f.blocked = f.blocked || block
+	f.mu.Unlock()
+
+	if block {
+		f.blockOnce <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var conns []rest.UserConnections
+	for _, id := range ids {
+		f.requested[id]++
+
+		user := models.User{ID: id}
+		for _, followerID := range f.followers[id] {
+			user.Followers = append(user.Followers, models.User{ID: followerID})
+		}
+
+		if id == 1 {
+			user.Subscriptions.Groups = []models.Group{{ID: 100}}
+		}
+
+		conns = append(conns, rest.UserConnections{User: user})
+	}
+
+	return conns, nil
+}
+
+type recordingRepo struct {
+	mu     sync.Mutex
+	users  map[uint64]int
+	groups map[uint64]int
+	edges  int
+}
+
+func newRecordingRepo() *recordingRepo {
+	return &recordingRepo{users: make(map[uint64]int), groups: make(map[uint64]int)}
+}
+
+func (r *recordingRepo) CreateUser(_ context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID]++
+	return nil
+}
+
+func (r *recordingRepo) CreateGroup(_ context.Context, group models.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups[group.ID]++
+	return nil
+}
+
+func (r *recordingRepo) CreateFollowRelationship(context.Context, models.User, models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edges++
+	return nil
+}
+
+func (r *recordingRepo) CreateSubscribeUserUserRelationship(context.Context, models.User, models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edges++
+	return nil
+}
+
+func (r *recordingRepo) CreateSubscribeUserGroupRelationship(context.Context, models.User, models.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edges++
+	return nil
+}
+
+func TestCrawlVisitsEachUserOnce(t *testing.T) {
+	client := newFakeClient()
+	repo := newRecordingRepo()
+
+	stats, err := New(client, repo, Config{Depth: 3, Workers: 2, BatchSize: 1}).Crawl(context.Background(), []uint64{1})
+	require.NoError(t, err)
+
+	// Уровни: {1}, {2, 3}, {4}. Пользователь 3 встречается дважды, но запрашивается один раз.
+	require.Equal(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 1}, client.requested)
+	require.Equal(t, uint64(4), stats.Visited)
+	require.Equal(t, uint64(4), stats.Users)
+	require.Equal(t, uint64(1), stats.Groups)
+	require.Equal(t, uint64(5), stats.Edges)
+	require.Equal(t, 5, repo.edges)
+}
+
+func TestCrawlFanOut(t *testing.T) {
+	client := newFakeClient()
+
+	stats, err := New(client, newRecordingRepo(), Config{Depth: 2, FanOut: []int{1}}).Crawl(context.Background(), []uint64{1})
+	require.NoError(t, err)
+
+	require.Equal(t, map[uint64]int{1: 1, 2: 1}, client.requested)
+	require.Equal(t, uint64(2), stats.Visited)
+}
+
+// TestCrawlFanOutSkipsVisited Уже посещенный сосед не занимает место в ограничении.
+func TestCrawlFanOutSkipsVisited(t *testing.T) {
+	client := newFakeClient()
+	client.followers[1] = []uint64{2, 5}
+
+	cfg := Config{Depth: 2, Workers: 1, BatchSize: 1, FanOut: []int{1}}
+	_, err := New(client, newRecordingRepo(), cfg).Crawl(context.Background(), []uint64{1, 2})
+	require.NoError(t, err)
+
+	require.Equal(t, map[uint64]int{1: 1, 2: 1, 3: 1, 5: 1}, client.requested)
+}
+
+func TestCrawlCountsEachEdgeOnce(t *testing.T) {
+	client := newFakeClient()
+	client.followers[1] = []uint64{2, 3, 2}
+	repo := newRecordingRepo()
+
+	stats, err := New(client, repo, Config{Depth: 1}).Crawl(context.Background(), []uint64{1})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(3), stats.Edges)
+	require.Equal(t, 3, repo.edges)
+}
+
+// TestResumeAfterLastBatchOfLevel Состояние сохранено после последней пачки уровня 1, но до перехода
+// на уровень 2: обход продолжается со следующего уровня.
+func TestResumeAfterLastBatchOfLevel(t *testing.T) {
+	client := newFakeClient()
+
+	stats, err := New(client, newRecordingRepo(), Config{Depth: 3}).Resume(context.Background(), State{
+		Level:    1,
+		Frontier: []uint64{},
+		Next:     []uint64{4},
+		Visited:  []uint64{1, 2, 3, 4},
+		Stats:    Stats{Visited: 4, Users: 3},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[uint64]int{4: 1}, client.requested)
+	require.Equal(t, uint64(4), stats.Users)
+}
+
+// TestResumeRefetchesInterruptedUser Смещения страниц не сохраняются: пользователь, на котором
+// обход прервался, остается в frontier и при продолжении запрашивается целиком.
+func TestResumeRefetchesInterruptedUser(t *testing.T) {
+	client := newFakeClient()
+	client.blockOnce = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := New(client, newRecordingRepo(), Config{Depth: 1})
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Crawl(ctx, []uint64{1})
+		done <- err
+	}()
+
+	<-client.blockOnce
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	state := c.State()
+	require.Equal(t, []uint64{1}, state.Frontier)
+
+	repo := newRecordingRepo()
+	stats, err := New(client, repo, Config{Depth: 1}).Resume(context.Background(), state)
+	require.NoError(t, err)
+
+	require.Equal(t, map[uint64]int{1: 1}, client.requested)
+	require.Equal(t, 3, repo.edges)
+	require.Equal(t, uint64(3), stats.Edges)
+}
+
+func TestCrawlFakeVK(t *testing.T) {
+	graph := vkfake.NewGraph()
+	graph.Follow(2, 1)
+	graph.Follow(3, 1)
+	graph.Follow(3, 2)
+	graph.Follow(4, 3)
+	graph.Subscribe(3, 5)
+	graph.SubscribeGroup(1, 100)
+	graph.SubscribeGroup(5, 200)
+
+	srv := vkfake.NewServer(graph)
+	defer srv.Close()
+
+	// Закрытый профиль не прерывает обход.
+	srv.AddFault(vkfake.Fault{Method: vkfake.MethodGetSubscriptions, UserID: 2, Code: vkfake.CodePrivateProfile})
+
+	client := rest.NewVKClient([]string{srv.URL}, []string{"token"}, rest.WithRateLimit(0, 0))
+	repo := memory.NewUserMemoryRepo()
+
+	stats, err := New(client, repo, Config{Depth: 3, Workers: 2, BatchSize: 2}).Crawl(context.Background(), []uint64{1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), stats.Visited)
+
+	users, err := repo.GetUsersCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 5, users)
+
+	groups, err := repo.GetGroupsCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, groups)
+
+	top, err := repo.GetTopUsersByFollowersCount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), top[0].ID)
+}
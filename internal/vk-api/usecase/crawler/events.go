@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"sync"
+	"time"
+)
+
+// eventBuffer Размер буфера подписчика. Медленный подписчик теряет события, а не тормозит обход.
+const eventBuffer = 256
+
+type EventType string
+
+const (
+	EventUserVisited   EventType = "user_visited"
+	EventEdgesWritten  EventType = "edges_written"
+	EventVKError       EventType = "vk_error"
+	EventRateLimitWait EventType = "rate_limit_wait"
+	EventLevelDone     EventType = "level_completed"
+	EventStatus        EventType = "status"
+)
+
+// Event Событие задачи обхода.
+type Event struct {
+	JobID  string    `json:"job_id"`
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	Level  int       `json:"level"`
+	UserID uint64    `json:"user_id,omitempty"`
+	Name   string    `json:"name,omitempty"`
+	Count  int       `json:"count,omitempty"`
+	Method string    `json:"method,omitempty"`
+	WaitMS int64     `json:"wait_ms,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Status Status    `json:"status,omitempty"`
+	Stats  *Stats    `json:"stats,omitempty"`
+}
+
+// Final Событие о завершении работы задачи (пауза, завершение, ошибка, отмена).
+func (e Event) Final() bool {
+	return e.Type == EventStatus && e.Status != StatusRunning
+}
+
+// broker Рассылает события задач подписчикам.
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+func (b *broker) subscribe(jobID string) (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[jobID] == nil {
+		b.subscribers[jobID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[jobID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[jobID], ch)
+			if len(b.subscribers[jobID]) == 0 {
+				delete(b.subscribers, jobID)
+			}
+		})
+	}
+}
+
+func (b *broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[e.JobID] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
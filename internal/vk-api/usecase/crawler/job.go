@@ -0,0 +1,447 @@
+package crawler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"slices"
+	"sync"
+	"time"
+)
+
+const DefaultCheckpointInterval = 5 * time.Second
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusPaused    Status = "paused"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+var (
+	ErrJobNotRunning = errors.New("crawl job is not running")
+	ErrJobNotPaused  = errors.New("crawl job is not paused")
+	ErrJobFinished   = errors.New("crawl job is already finished")
+	ErrInvalidConfig = errors.New("invalid crawl config")
+
+	errPaused   = errors.New("crawl job paused")
+	errCanceled = errors.New("crawl job canceled")
+)
+
+// Checkpoint Сохраняемое состояние задачи обхода.
+type Checkpoint struct {
+	ID        string    `json:"id"`
+	Seeds     []uint64  `json:"seeds"`
+	Config    Config    `json:"config"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	State     State     `json:"state"`
+}
+
+// JobInfo Сведения о задаче обхода для отображения прогресса.
+type JobInfo struct {
+	ID           string    `json:"id"`
+	Seeds        []uint64  `json:"seeds"`
+	Config       Config    `json:"config"`
+	Status       Status    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Level        int       `json:"level"`
+	FrontierSize int       `json:"frontier_size"`
+	NextSize     int       `json:"next_size"`
+	Stats        Stats     `json:"stats"`
+}
+
+type job struct {
+	mu       sync.Mutex
+	cp       Checkpoint
+	crawler  *Crawler
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	lastSave time.Time
+}
+
+func (j *job) info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	info := JobInfo{
+		ID:           j.cp.ID,
+		Seeds:        j.cp.Seeds,
+		Config:       j.cp.Config,
+		Status:       j.cp.Status,
+		Error:        j.cp.Error,
+		CreatedAt:    j.cp.CreatedAt,
+		UpdatedAt:    j.cp.UpdatedAt,
+		Level:        j.cp.State.Level,
+		FrontierSize: len(j.cp.State.Frontier),
+		NextSize:     len(j.cp.State.Next),
+		Stats:        j.cp.State.Stats,
+	}
+
+	if j.crawler != nil {
+		info.Level, info.FrontierSize, info.NextSize, info.Stats = j.crawler.Progress()
+	}
+
+	return info
+}
+
+// Manager Запускает задачи обхода в фоне, периодически сохраняет их контрольные точки
+// и позволяет приостанавливать и продолжать задачи, в том числе после перезапуска процесса.
+type Manager struct {
+	ctx    context.Context
+	client VKClient
+	repo   Repository
+	store  Store
+
+	defaults           Config
+	checkpointInterval time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*job
+
+	events *broker
+}
+
+// NewManager Создает менеджер задач. Незаданные параметры задач берутся из defaults.
+// Отмена ctx останавливает задачи без смены статуса, поэтому после перезапуска Restore продолжит их.
+func NewManager(ctx context.Context, client VKClient, repo Repository, store Store, defaults Config,
+	checkpointInterval time.Duration) *Manager {
+	if checkpointInterval <= 0 {
+		checkpointInterval = DefaultCheckpointInterval
+	}
+
+	return &Manager{
+		ctx:                ctx,
+		client:             client,
+		repo:               repo,
+		store:              store,
+		defaults:           defaults,
+		checkpointInterval: checkpointInterval,
+		jobs:               make(map[string]*job),
+		events:             newBroker(),
+	}
+}
+
+// Restore Загружает сохраненные задачи и продолжает те, что выполнялись в момент остановки.
+func (m *Manager) Restore(ctx context.Context) error {
+	checkpoints, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("m.store.List: %w", err)
+	}
+
+	for _, cp := range checkpoints {
+		m.mu.Lock()
+		if _, ok := m.jobs[cp.ID]; ok {
+			m.mu.Unlock()
+			continue
+		}
+
+		j := &job{cp: cp}
+		m.jobs[cp.ID] = j
+		m.mu.Unlock()
+
+		if cp.Status == StatusRunning {
+			log.Info().Msgf("Продолжение обхода %s с уровня %d", cp.ID, cp.State.Level)
+			m.run(j, true, nil)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) Start(seeds []uint64, cfg Config) (JobInfo, error) {
+	if len(seeds) == 0 {
+		return JobInfo{}, fmt.Errorf("%w: no seeds to crawl", ErrInvalidConfig)
+	}
+
+	cfg = m.withDefaults(cfg)
+	if cfg.Depth <= 0 {
+		return JobInfo{}, fmt.Errorf("%w: depth must be positive, got %d", ErrInvalidConfig, cfg.Depth)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	now := time.Now().UTC()
+	j := &job{cp: Checkpoint{
+		ID:        id,
+		Seeds:     seeds,
+		Config:    cfg,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+
+	if err := m.store.Save(m.ctx, j.cp); err != nil {
+		return JobInfo{}, fmt.Errorf("m.store.Save: %w", err)
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	m.run(j, false, nil)
+
+	return j.info(), nil
+}
+
+func (m *Manager) withDefaults(cfg Config) Config {
+	if cfg.Depth == 0 {
+		cfg.Depth = m.defaults.Depth
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = m.defaults.Workers
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = m.defaults.BatchSize
+	}
+	if len(cfg.FanOut) == 0 {
+		cfg.FanOut = m.defaults.FanOut
+	}
+	if cfg.MaxUsers == 0 {
+		cfg.MaxUsers = m.defaults.MaxUsers
+	}
+
+	return cfg
+}
+
+func (m *Manager) Get(id string) (JobInfo, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	return j.info(), nil
+}
+
+func (m *Manager) List() []JobInfo {
+	m.mu.Lock()
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	m.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		infos = append(infos, j.info())
+	}
+
+	slices.SortFunc(infos, func(a, b JobInfo) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return infos
+}
+
+// Pause Останавливает задачу, сохраняя контрольную точку. Дожидается остановки воркеров.
+func (m *Manager) Pause(id string) (JobInfo, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	j.mu.Lock()
+	if j.cp.Status != StatusRunning || j.cancel == nil {
+		j.mu.Unlock()
+		return JobInfo{}, ErrJobNotRunning
+	}
+	cancel, done := j.cancel, j.done
+	j.mu.Unlock()
+
+	cancel(errPaused)
+	<-done
+
+	return j.info(), nil
+}
+
+// Cancel Отменяет задачу. Отмененную задачу нельзя продолжить, контрольная точка сохраняется для истории.
+func (m *Manager) Cancel(id string) (JobInfo, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	j.mu.Lock()
+	switch {
+	case j.cancel != nil:
+		cancel, done := j.cancel, j.done
+		j.mu.Unlock()
+
+		cancel(errCanceled)
+		<-done
+	case j.cp.Status == StatusPaused || j.cp.Status == StatusFailed:
+		j.cp.Status = StatusCanceled
+		j.mu.Unlock()
+
+		m.checkpoint(j, true)
+	default:
+		j.mu.Unlock()
+		return JobInfo{}, ErrJobFinished
+	}
+
+	return j.info(), nil
+}
+
+// Resume Продолжает приостановленную или завершившуюся ошибкой задачу с контрольной точки.
+func (m *Manager) Resume(id string) (JobInfo, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	err = m.run(j, true, func(status Status) error {
+		if status != StatusPaused && status != StatusFailed {
+			return ErrJobNotPaused
+		}
+
+		return nil
+	})
+	if err != nil {
+		return JobInfo{}, err
+	}
+
+	return j.info(), nil
+}
+
+// Subscribe Подписывает на события задачи. Возвращает текущее состояние задачи, канал событий
+// и функцию отписки, которую нужно вызвать по окончании чтения.
+func (m *Manager) Subscribe(id string) (JobInfo, <-chan Event, func(), error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobInfo{}, nil, nil, err
+	}
+
+	events, unsubscribe := m.events.subscribe(id)
+
+	return j.info(), events, unsubscribe, nil
+}
+
+func (m *Manager) job(id string) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	return j, nil
+}
+
+// run Запускает обход задачи. Если задан ready, он проверяет статус под той же блокировкой, под которой
+// задача переводится в running, чтобы одновременные вызовы не запустили два обхода.
+func (m *Manager) run(j *job, resume bool, ready func(Status) error) error {
+	j.mu.Lock()
+	if ready != nil {
+		if err := ready(j.cp.Status); err != nil {
+			j.mu.Unlock()
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	c := New(m.client, m.repo, j.cp.Config)
+	c.OnProgress(func() { m.checkpoint(j, false) })
+	c.OnEvent(func(e Event) {
+		e.JobID = j.cp.ID
+		m.events.publish(e)
+	})
+
+	j.crawler = c
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	j.cp.Status = StatusRunning
+	j.cp.Error = ""
+	seeds, state, done := j.cp.Seeds, j.cp.State, j.done
+	j.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel(nil)
+
+		var err error
+		if resume {
+			_, err = c.Resume(ctx, state)
+		} else {
+			_, err = c.Crawl(ctx, seeds)
+		}
+
+		j.mu.Lock()
+		j.cp.State = c.State()
+		switch {
+		case err == nil:
+			j.cp.Status = StatusCompleted
+		case errors.Is(context.Cause(ctx), errPaused):
+			j.cp.Status = StatusPaused
+		case errors.Is(context.Cause(ctx), errCanceled):
+			j.cp.Status = StatusCanceled
+		case m.ctx.Err() != nil:
+			// Остановка процесса: статус running сохраняется, чтобы Restore продолжил задачу.
+		default:
+			j.cp.Status = StatusFailed
+			j.cp.Error = err.Error()
+		}
+		j.crawler = nil
+		j.cancel = nil
+		j.mu.Unlock()
+
+		m.checkpoint(j, true)
+
+		info := j.info()
+		log.Info().Msgf("Обход %s остановлен: %s", info.ID, info.Status)
+		m.events.publish(Event{
+			JobID:  info.ID,
+			Type:   EventStatus,
+			Time:   time.Now().UTC(),
+			Level:  info.Level,
+			Status: info.Status,
+			Error:  info.Error,
+			Stats:  &info.Stats,
+		})
+	}()
+
+	return nil
+}
+
+// checkpoint Сохраняет состояние задачи не чаще checkpointInterval, если не force.
+func (m *Manager) checkpoint(j *job, force bool) {
+	j.mu.Lock()
+	if !force && time.Since(j.lastSave) < m.checkpointInterval {
+		j.mu.Unlock()
+		return
+	}
+
+	j.lastSave = time.Now()
+	if j.crawler != nil {
+		j.cp.State = j.crawler.State()
+	}
+	j.cp.UpdatedAt = time.Now().UTC()
+	cp := j.cp
+	j.mu.Unlock()
+
+	if err := m.store.Save(context.WithoutCancel(m.ctx), cp); err != nil {
+		log.Error().Err(err).Msgf("Не удалось сохранить контрольную точку обхода %s", cp.ID)
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
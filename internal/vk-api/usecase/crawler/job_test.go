@@ -0,0 +1,210 @@
+package crawler
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitStatus(t *testing.T, m *Manager, id string, status Status) JobInfo {
+	t.Helper()
+
+	var info JobInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = m.Get(id)
+		require.NoError(t, err)
+		return info.Status == status
+	}, time.Second, 10*time.Millisecond)
+
+	return info
+}
+
+func TestManagerPauseAndResumeAfterRestart(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	client.blockOnce = make(chan struct{})
+
+	m := NewManager(context.Background(), client, newRecordingRepo(), store, Config{}, time.Hour)
+
+	info, err := m.Start([]uint64{1}, Config{Depth: 3})
+	require.NoError(t, err)
+
+	// Первый запрос завис, приостанавливаем задачу.
+	<-client.blockOnce
+
+	paused, err := m.Pause(info.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPaused, paused.Status)
+
+	cp, err := store.Load(context.Background(), info.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPaused, cp.Status)
+	require.Equal(t, []uint64{1}, cp.State.Frontier)
+	require.Equal(t, []uint64{1}, cp.State.Visited)
+
+	// Новый менеджер поверх того же хранилища: задача восстанавливается на паузе и продолжается по запросу.
+	restored := NewManager(context.Background(), client, newRecordingRepo(), store, Config{}, time.Hour)
+	require.NoError(t, restored.Restore(context.Background()))
+
+	info, err = restored.Get(info.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPaused, info.Status)
+
+	_, err = restored.Resume(info.ID)
+	require.NoError(t, err)
+
+	done := waitStatus(t, restored, info.ID, StatusCompleted)
+	require.Equal(t, uint64(4), done.Stats.Visited)
+	require.Equal(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 1}, client.requested)
+}
+
+func TestManagerRestoreRunning(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(context.Background(), Checkpoint{
+		ID:     "interrupted",
+		Seeds:  []uint64{1},
+		Config: Config{Depth: 2},
+		Status: StatusRunning,
+		State: State{
+			Level:    1,
+			Frontier: []uint64{3},
+			Visited:  []uint64{1, 2, 3},
+			Stats:    Stats{Visited: 2},
+		},
+	}))
+
+	client := newFakeClient()
+	m := NewManager(context.Background(), client, newRecordingRepo(), store, Config{}, time.Hour)
+	require.NoError(t, m.Restore(context.Background()))
+
+	info := waitStatus(t, m, "interrupted", StatusCompleted)
+	require.Equal(t, uint64(3), info.Stats.Visited)
+	require.Equal(t, map[uint64]int{3: 1}, client.requested)
+}
+
+func TestManagerConcurrentResume(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(context.Background(), Checkpoint{
+		ID:     "paused",
+		Seeds:  []uint64{1},
+		Config: Config{Depth: 3},
+		Status: StatusPaused,
+		State:  State{Frontier: []uint64{1}, Visited: []uint64{1}},
+	}))
+
+	client := newFakeClient()
+	m := NewManager(context.Background(), client, newRecordingRepo(), store, Config{}, time.Hour)
+	require.NoError(t, m.Restore(context.Background()))
+
+	// Одновременные запросы продолжения: обход запускается только один раз.
+	const callers = 8
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		errs  = make(chan error, callers)
+	)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			_, err := m.Resume("paused")
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	resumed := 0
+	for err := range errs {
+		if err == nil {
+			resumed++
+			continue
+		}
+		require.ErrorIs(t, err, ErrJobNotPaused)
+	}
+	require.Equal(t, 1, resumed)
+
+	waitStatus(t, m, "paused", StatusCompleted)
+	require.Equal(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 1}, client.requested)
+}
+
+func TestManagerCancel(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	client.blockOnce = make(chan struct{})
+
+	m := NewManager(context.Background(), client, newRecordingRepo(), store, Config{Depth: 2}, time.Hour)
+
+	info, err := m.Start([]uint64{1}, Config{})
+	require.NoError(t, err)
+	require.Equal(t, 2, info.Config.Depth)
+
+	<-client.blockOnce
+
+	canceled, err := m.Cancel(info.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusCanceled, canceled.Status)
+
+	_, err = m.Cancel(info.ID)
+	require.ErrorIs(t, err, ErrJobFinished)
+
+	_, err = m.Resume(info.ID)
+	require.ErrorIs(t, err, ErrJobNotPaused)
+}
+
+func TestManagerEvents(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	client.blockOnce = make(chan struct{})
+
+	m := NewManager(context.Background(), client, newRecordingRepo(), store, Config{}, time.Hour)
+
+	info, err := m.Start([]uint64{1}, Config{Depth: 2})
+	require.NoError(t, err)
+
+	snapshot, events, unsubscribe, err := m.Subscribe(info.ID)
+	require.NoError(t, err)
+	defer unsubscribe()
+	require.Equal(t, StatusRunning, snapshot.Status)
+
+	// Задача ждет первый запрос, подписка уже оформлена: отменяем и продолжаем, чтобы увидеть весь поток.
+	<-client.blockOnce
+	_, err = m.Pause(info.ID)
+	require.NoError(t, err)
+	_, err = m.Resume(info.ID)
+	require.NoError(t, err)
+
+	types := make(map[EventType]int)
+	var final []Status
+	for e := range events {
+		require.Equal(t, info.ID, e.JobID)
+		types[e.Type]++
+
+		if e.Final() {
+			final = append(final, e.Status)
+			if e.Status == StatusCompleted {
+				break
+			}
+		}
+	}
+
+	require.Equal(t, []Status{StatusPaused, StatusCompleted}, final)
+	require.Equal(t, 3, types[EventUserVisited])
+	require.Equal(t, 2, types[EventLevelDone])
+}
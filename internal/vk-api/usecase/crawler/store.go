@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrJobNotFound = errors.New("crawl job not found")
+
+// Store Хранилище контрольных точек задач обхода.
+type Store interface {
+	Save(ctx context.Context, cp Checkpoint) error
+	Load(ctx context.Context, id string) (Checkpoint, error)
+	List(ctx context.Context) ([]Checkpoint, error)
+}
+
+// FileStore Хранит контрольные точки в каталоге, по одному JSON файлу на задачу.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save Записывает контрольную точку атомарно: через временный файл и переименование.
+func (s *FileStore) Save(_ context.Context, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, cp.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(cp.ID))
+}
+
+func (s *FileStore) Load(_ context.Context, id string) (Checkpoint, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint %s: %w", id, err)
+	}
+
+	return cp, nil
+}
+
+func (s *FileStore) List(ctx context.Context) ([]Checkpoint, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints []Checkpoint
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		cp, err := s.Load(ctx, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		checkpoints = append(checkpoints, cp)
+	}
+
+	return checkpoints, nil
+}
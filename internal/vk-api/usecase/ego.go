@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+const (
+	DefaultEgoHops  = 1
+	MaxEgoHops      = 3
+	DefaultEgoLimit = 200
+	MaxEgoLimit     = 2000
+)
+
+var ErrNodeNotFound = errors.New("node not found")
+
+// EgoNetwork Подграф из узла и его окрестности радиусом query.Hops со связями между ними.
+func (uc *UserUsecase) EgoNetwork(ctx context.Context, query models.EgoQuery) (models.Subgraph, error) {
+	if err := checkEgoQuery(&query); err != nil {
+		return models.Subgraph{}, err
+	}
+
+	subgraph, err := uc.repo.GetEgoNetwork(ctx, query)
+	if err != nil {
+		return models.Subgraph{}, fmt.Errorf("uc.repo.GetEgoNetwork: %w", err)
+	}
+
+	if subgraph == nil {
+		return models.Subgraph{}, ErrNodeNotFound
+	}
+
+	return *subgraph, nil
+}
+
+// checkEgoQuery Проверяет запрос и заполняет умолчания: DefaultEgoHops, DefaultEgoLimit и умолчания обхода.
+func checkEgoQuery(query *models.EgoQuery) error {
+	if err := checkTraversal(&query.Types, &query.Direction); err != nil {
+		return err
+	}
+
+	switch {
+	case query.Hops == 0:
+		query.Hops = DefaultEgoHops
+	case query.Hops < 0 || query.Hops > MaxEgoHops:
+		return fmt.Errorf("%w: hops must be between 1 and %d", ErrInvalidQuery, MaxEgoHops)
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultEgoLimit
+	case query.Limit < 0 || query.Limit > MaxEgoLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxEgoLimit)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/export"
+)
+
+// ExportGraph Выгружает подграф по фильтру. Выгрузка открывается вызовом open только после проверки фильтра,
+// чтобы ошибка запроса не попала в уже начатый документ, и закрывается после записи последней связи.
+func (uc *UserUsecase) ExportGraph(ctx context.Context, filter models.ExportFilter, open func() (export.Writer, error)) error {
+	if err := checkExportFilter(&filter); err != nil {
+		return err
+	}
+
+	w, err := open()
+	if err != nil {
+		return err
+	}
+
+	if err := uc.repo.ExportGraph(ctx, filter, w.WriteNode, w.WriteRelationship); err != nil {
+		return fmt.Errorf("uc.repo.ExportGraph: %w", err)
+	}
+
+	return w.Close()
+}
+
+func checkExportFilter(filter *models.ExportFilter) error {
+	switch filter.Label {
+	case "", models.LabelUser, models.LabelGroup:
+	default:
+		return fmt.Errorf("%w: unknown label %q, use User or Group", ErrInvalidQuery, filter.Label)
+	}
+
+	if filter.Sex != nil && *filter.Sex > 2 {
+		return fmt.Errorf("%w: sex must be 0, 1 or 2", ErrInvalidQuery)
+	}
+
+	return checkTypes(&filter.Types)
+}
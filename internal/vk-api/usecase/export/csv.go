@@ -0,0 +1,148 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+)
+
+const (
+	NodesFileName = "nodes.csv"
+	EdgesFileName = "edges.csv"
+)
+
+var edgesHeader = []string{"source", "target", "type"}
+
+func nodesHeader() []string {
+	header := []string{"id", "label", "vk_id"}
+	for _, attr := range nodeAttributes {
+		header = append(header, attr.name)
+	}
+
+	return header
+}
+
+func nodeRecord(node models.Node) []string {
+	record := []string{nodeKey(node.Label, node.ID), node.Label, strconv.FormatUint(node.ID, 10)}
+	for _, attr := range nodeAttributes {
+		value, _ := property(node, attr.name)
+		record = append(record, value)
+	}
+
+	return record
+}
+
+func edgeRecord(rel models.Relationship) []string {
+	return []string{nodeKey(models.LabelUser, rel.SourceID), nodeKey(rel.TargetLabel, rel.TargetID), rel.Type}
+}
+
+// csvWriter Узлы и связи в отдельные потоки, порядок не важен.
+type csvWriter struct {
+	nodes *csv.Writer
+	edges *csv.Writer
+}
+
+// NewCSVWriter Выгрузка в пару CSV: узлы в nodes, связи в edges.
+func NewCSVWriter(nodes, edges io.Writer) (Writer, error) {
+	w := &csvWriter{nodes: csv.NewWriter(nodes), edges: csv.NewWriter(edges)}
+	if err := w.nodes.Write(nodesHeader()); err != nil {
+		return nil, err
+	}
+
+	if err := w.edges.Write(edgesHeader); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *csvWriter) WriteNode(node models.Node) error {
+	return w.nodes.Write(nodeRecord(node))
+}
+
+func (w *csvWriter) WriteRelationship(rel models.Relationship) error {
+	return w.edges.Write(edgeRecord(rel))
+}
+
+func (w *csvWriter) Close() error {
+	for _, cw := range []*csv.Writer{w.nodes, w.edges} {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zipCSVWriter Пара CSV в одном zip архиве. Файлы архива пишутся по очереди, поэтому узлы идут до связей.
+type zipCSVWriter struct {
+	zip   *zip.Writer
+	nodes *csv.Writer
+	edges *csv.Writer
+}
+
+func newZipCSVWriter(w io.Writer) (*zipCSVWriter, error) {
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(NodesFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := csv.NewWriter(f)
+	if err := nodes.Write(nodesHeader()); err != nil {
+		return nil, err
+	}
+
+	return &zipCSVWriter{zip: zw, nodes: nodes}, nil
+}
+
+func (w *zipCSVWriter) WriteNode(node models.Node) error {
+	if w.edges != nil {
+		return errNodeAfterEdges
+	}
+
+	return w.nodes.Write(nodeRecord(node))
+}
+
+func (w *zipCSVWriter) WriteRelationship(rel models.Relationship) error {
+	if err := w.startEdges(); err != nil {
+		return err
+	}
+
+	return w.edges.Write(edgeRecord(rel))
+}
+
+func (w *zipCSVWriter) startEdges() error {
+	if w.edges != nil {
+		return nil
+	}
+
+	w.nodes.Flush()
+	if err := w.nodes.Error(); err != nil {
+		return err
+	}
+
+	f, err := w.zip.Create(EdgesFileName)
+	if err != nil {
+		return err
+	}
+
+	w.edges = csv.NewWriter(f)
+	return w.edges.Write(edgesHeader)
+}
+
+func (w *zipCSVWriter) Close() error {
+	if err := w.startEdges(); err != nil {
+		return err
+	}
+
+	w.edges.Flush()
+	if err := w.edges.Error(); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// dotQuote Строка DOT в двойных кавычках.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// dotWriter Graphviz DOT: пользователи - эллипсы, группы - прямоугольники, подпись - имя узла.
+type dotWriter struct {
+	w *bufio.Writer
+}
+
+func newDOTWriter(w io.Writer) (*dotWriter, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("digraph vk {\n"); err != nil {
+		return nil, err
+	}
+
+	return &dotWriter{w: bw}, nil
+}
+
+func (w *dotWriter) WriteNode(node models.Node) error {
+	key := nodeKey(node.Label, node.ID)
+	label, ok := property(node, "name")
+	if !ok {
+		label = key
+	}
+
+	shape := "ellipse"
+	if node.Label == models.LabelGroup {
+		shape = "box"
+	}
+
+	attrs := []string{
+		"label=" + dotQuote(label),
+		"shape=" + shape,
+		"kind=" + dotQuote(node.Label),
+		"vk_id=" + strconv.FormatUint(node.ID, 10),
+	}
+	for _, attr := range nodeAttributes {
+		if value, ok := property(node, attr.name); ok {
+			attrs = append(attrs, attr.name+"="+dotQuote(value))
+		}
+	}
+
+	_, err := fmt.Fprintf(w.w, "\t%s [%s];\n", dotQuote(key), strings.Join(attrs, ", "))
+	return err
+}
+
+func (w *dotWriter) WriteRelationship(rel models.Relationship) error {
+	_, err := fmt.Fprintf(w.w, "\t%s -> %s [label=%s];\n",
+		dotQuote(nodeKey(models.LabelUser, rel.SourceID)),
+		dotQuote(nodeKey(rel.TargetLabel, rel.TargetID)),
+		dotQuote(rel.Type),
+	)
+	return err
+}
+
+func (w *dotWriter) Close() error {
+	if _, err := w.w.WriteString("}\n"); err != nil {
+		return err
+	}
+
+	return w.w.Flush()
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatGraphML = "graphml"
+	FormatGEXF    = "gexf"
+	FormatDOT     = "dot"
+	// FormatCSV Пара nodes.csv и edges.csv. В один поток записывается zip архивом.
+	FormatCSV = "csv"
+)
+
+// Formats Форматы выгрузки в один поток.
+var Formats = []string{FormatGraphML, FormatGEXF, FormatDOT, FormatCSV}
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+	// errNodeAfterEdges Форматы с разделами узлов и связей не позволяют вернуться к узлам.
+	errNodeAfterEdges = errors.New("node written after relationships")
+)
+
+// Writer Выгрузка графа в одном из форматов. Все узлы передаются до связей.
+type Writer interface {
+	WriteNode(node models.Node) error
+	WriteRelationship(rel models.Relationship) error
+	// Close Дописывает окончание документа. Нижележащий io.Writer не закрывается.
+	Close() error
+}
+
+// NewWriter Выгрузка в формате format в один поток.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatGraphML:
+		return newGraphMLWriter(w)
+	case FormatGEXF:
+		return newGEXFWriter(w)
+	case FormatDOT:
+		return newDOTWriter(w)
+	case FormatCSV:
+		return newZipCSVWriter(w)
+	}
+
+	return nil, fmt.Errorf("%w %q, use graphml, gexf, dot or csv", ErrUnknownFormat, format)
+}
+
+// ContentType MIME тип выгрузки в один поток.
+func ContentType(format string) string {
+	switch format {
+	case FormatGraphML, FormatGEXF:
+		return "application/xml"
+	case FormatDOT:
+		return "text/vnd.graphviz"
+	case FormatCSV:
+		return "application/zip"
+	}
+
+	return "application/octet-stream"
+}
+
+// Extension Расширение файла выгрузки в один поток.
+func Extension(format string) string {
+	if format == FormatCSV {
+		return ".zip"
+	}
+
+	return "." + format
+}
+
+// nodeKey Идентификатор узла в выгрузке: у пользователя и группы VK id могут совпадать.
+func nodeKey(label string, id uint64) string {
+	if label == models.LabelGroup {
+		return "g" + strconv.FormatUint(id, 10)
+	}
+
+	return "u" + strconv.FormatUint(id, 10)
+}
+
+// attribute Свойство узла, которое попадает в выгрузку.
+type attribute struct {
+	name string
+	// kind Тип для GraphML и GEXF.
+	kind string
+}
+
+// nodeAttributes Свойства узлов в порядке выгрузки. У групп есть только name и screen_name.
+var nodeAttributes = []attribute{
+	{"name", "string"},
+	{"screen_name", "string"},
+	{"sex", "int"},
+	{"city", "string"},
+}
+
+// property Свойство узла строкой, false - если его нет.
+func property(node models.Node, name string) (string, bool) {
+	switch v := node.Properties[name].(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
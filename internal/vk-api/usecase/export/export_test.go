@@ -0,0 +1,166 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+var (
+	testNodes = []models.Node{
+		{ID: 1, Label: models.LabelUser, Properties: map[string]interface{}{
+			"id": int64(1), "name": `Ivan "Vanya" Petrov`, "screen_name": "ivan", "sex": int64(2), "city": "Surgut",
+		}},
+		{ID: 1, Label: models.LabelGroup, Properties: map[string]interface{}{
+			"id": int64(1), "name": "Club & Co", "screen_name": "club",
+		}},
+	}
+	testRelationships = []models.Relationship{
+		{Type: models.RelationshipSubscribe, SourceID: 1, TargetID: 1, TargetLabel: models.LabelGroup},
+	}
+)
+
+func write(t *testing.T, w Writer) {
+	t.Helper()
+
+	for _, node := range testNodes {
+		require.NoError(t, w.WriteNode(node))
+	}
+
+	for _, rel := range testRelationships {
+		require.NoError(t, w.WriteRelationship(rel))
+	}
+
+	require.NoError(t, w.Close())
+}
+
+func TestGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatGraphML, &buf)
+	require.NoError(t, err)
+	write(t, w)
+
+	var doc struct {
+		Keys  []graphMLKey  `xml:"key"`
+		Nodes []graphMLNode `xml:"graph>node"`
+		Edges []graphMLEdge `xml:"graph>edge"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Keys, 7)
+	require.Len(t, doc.Nodes, 2)
+	require.Equal(t, "u1", doc.Nodes[0].ID)
+	require.Contains(t, doc.Nodes[0].Data, graphMLData{Key: "name", Value: `Ivan "Vanya" Petrov`})
+	require.Equal(t, "g1", doc.Nodes[1].ID)
+	require.Len(t, doc.Nodes[1].Data, 4)
+	require.Equal(t, []graphMLEdge{{
+		XMLName: xml.Name{Space: "http://graphml.graphdrawing.org/xmlns", Local: "edge"},
+		Source:  "u1",
+		Target:  "g1",
+		Data:    []graphMLData{{Key: "type", Value: models.RelationshipSubscribe}},
+	}}, doc.Edges)
+}
+
+func TestGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatGEXF, &buf)
+	require.NoError(t, err)
+	write(t, w)
+
+	var doc struct {
+		Attributes []gexfAttributes `xml:"graph>attributes"`
+		Nodes      []gexfNode       `xml:"graph>nodes>node"`
+		Edges      []gexfEdge       `xml:"graph>edges>edge"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Attributes, 2)
+	require.Len(t, doc.Nodes, 2)
+	require.Equal(t, `Ivan "Vanya" Petrov`, doc.Nodes[0].Label)
+	require.Equal(t, "Club & Co", doc.Nodes[1].Label)
+	require.Len(t, doc.Edges, 1)
+	require.Equal(t, "0", doc.Edges[0].ID)
+	require.Equal(t, "g1", doc.Edges[0].Target)
+
+	// После связей раздел узлов закрыт.
+	w, err = NewWriter(FormatGEXF, io.Discard)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRelationship(testRelationships[0]))
+	require.ErrorIs(t, w.WriteNode(testNodes[0]), errNodeAfterEdges)
+}
+
+func TestEmptyGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatGEXF, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var doc struct{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Contains(t, buf.String(), "<nodes></nodes>")
+	require.Contains(t, buf.String(), "<edges></edges>")
+}
+
+func TestDOT(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatDOT, &buf)
+	require.NoError(t, err)
+	write(t, w)
+
+	require.Equal(t, `digraph vk {
+	"u1" [label="Ivan \"Vanya\" Petrov", shape=ellipse, kind="User", vk_id=1, name="Ivan \"Vanya\" Petrov", screen_name="ivan", sex="2", city="Surgut"];
+	"g1" [label="Club & Co", shape=box, kind="Group", vk_id=1, name="Club & Co", screen_name="club"];
+	"u1" -> "g1" [label="Subscribe"];
+}
+`, buf.String())
+}
+
+const (
+	testNodesCSV = `id,label,vk_id,name,screen_name,sex,city
+u1,User,1,"Ivan ""Vanya"" Petrov",ivan,2,Surgut
+g1,Group,1,Club & Co,club,,
+`
+	testEdgesCSV = `source,target,type
+u1,g1,Subscribe
+`
+)
+
+func TestCSV(t *testing.T) {
+	var nodes, edges bytes.Buffer
+	w, err := NewCSVWriter(&nodes, &edges)
+	require.NoError(t, err)
+	write(t, w)
+
+	require.Equal(t, testNodesCSV, nodes.String())
+	require.Equal(t, testEdgesCSV, edges.String())
+}
+
+func TestZipCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+	write(t, w)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.File, 2)
+
+	for i, expected := range map[int]string{0: testNodesCSV, 1: testEdgesCSV} {
+		f, err := r.File[i].Open()
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(data))
+	}
+
+	require.Equal(t, NodesFileName, r.File[0].Name)
+	require.Equal(t, EdgesFileName, r.File[1].Name)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", io.Discard)
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"encoding/xml"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+)
+
+type gexfAttribute struct {
+	XMLName xml.Name `xml:"attribute"`
+	ID      string   `xml:"id,attr"`
+	Title   string   `xml:"title,attr"`
+	Type    string   `xml:"type,attr"`
+}
+
+type gexfAttributes struct {
+	XMLName    xml.Name        `xml:"attributes"`
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	XMLName   xml.Name       `xml:"node"`
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	XMLName   xml.Name       `xml:"edge"`
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+var (
+	gexfRoot = xml.StartElement{
+		Name: xml.Name{Local: "gexf"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: "http://gexf.net/1.3"},
+			{Name: xml.Name{Local: "version"}, Value: "1.3"},
+		},
+	}
+	gexfGraph = xml.StartElement{
+		Name: xml.Name{Local: "graph"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "mode"}, Value: "static"},
+			{Name: xml.Name{Local: "defaultedgetype"}, Value: "directed"},
+		},
+	}
+	gexfNodes = xml.StartElement{Name: xml.Name{Local: "nodes"}}
+	gexfEdges = xml.StartElement{Name: xml.Name{Local: "edges"}}
+)
+
+// gexfWriter GEXF для Gephi. Разделы nodes и edges идут друг за другом, поэтому узлы пишутся до связей.
+type gexfWriter struct {
+	enc    *xml.Encoder
+	edges  bool
+	edgeID int
+}
+
+func newGEXFWriter(w io.Writer) (*gexfWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	for _, token := range []xml.StartElement{gexfRoot, gexfGraph} {
+		if err := enc.EncodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+
+	nodeAttrs := gexfAttributes{Class: "node", Attributes: []gexfAttribute{
+		{ID: "label", Title: "label", Type: "string"},
+		{ID: "vk_id", Title: "vk_id", Type: "long"},
+	}}
+	for _, attr := range nodeAttributes {
+		nodeAttrs.Attributes = append(nodeAttrs.Attributes, gexfAttribute{ID: attr.name, Title: attr.name, Type: attr.kind})
+	}
+	edgeAttrs := gexfAttributes{Class: "edge", Attributes: []gexfAttribute{{ID: "type", Title: "type", Type: "string"}}}
+
+	for _, attrs := range []gexfAttributes{nodeAttrs, edgeAttrs} {
+		if err := enc.Encode(attrs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.EncodeToken(gexfNodes); err != nil {
+		return nil, err
+	}
+
+	return &gexfWriter{enc: enc}, nil
+}
+
+func (w *gexfWriter) WriteNode(node models.Node) error {
+	if w.edges {
+		return errNodeAfterEdges
+	}
+
+	key := nodeKey(node.Label, node.ID)
+	label, ok := property(node, "name")
+	if !ok {
+		label = key
+	}
+
+	values := []gexfAttValue{
+		{For: "label", Value: node.Label},
+		{For: "vk_id", Value: strconv.FormatUint(node.ID, 10)},
+	}
+	for _, attr := range nodeAttributes {
+		if value, ok := property(node, attr.name); ok {
+			values = append(values, gexfAttValue{For: attr.name, Value: value})
+		}
+	}
+
+	return w.enc.Encode(gexfNode{ID: key, Label: label, AttValues: values})
+}
+
+func (w *gexfWriter) WriteRelationship(rel models.Relationship) error {
+	if err := w.startEdges(); err != nil {
+		return err
+	}
+
+	edge := gexfEdge{
+		ID:        strconv.Itoa(w.edgeID),
+		Source:    nodeKey(models.LabelUser, rel.SourceID),
+		Target:    nodeKey(rel.TargetLabel, rel.TargetID),
+		Label:     rel.Type,
+		AttValues: []gexfAttValue{{For: "type", Value: rel.Type}},
+	}
+	w.edgeID++
+
+	return w.enc.Encode(edge)
+}
+
+func (w *gexfWriter) startEdges() error {
+	if w.edges {
+		return nil
+	}
+	w.edges = true
+
+	if err := w.enc.EncodeToken(gexfNodes.End()); err != nil {
+		return err
+	}
+
+	return w.enc.EncodeToken(gexfEdges)
+}
+
+func (w *gexfWriter) Close() error {
+	if err := w.startEdges(); err != nil {
+		return err
+	}
+
+	for _, token := range []xml.EndElement{gexfEdges.End(), gexfGraph.End(), gexfRoot.End()} {
+		if err := w.enc.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+
+	return w.enc.Close()
+}
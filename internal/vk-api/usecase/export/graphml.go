@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/xml"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+)
+
+type graphMLKey struct {
+	XMLName xml.Name `xml:"key"`
+	ID      string   `xml:"id,attr"`
+	For     string   `xml:"for,attr"`
+	Name    string   `xml:"attr.name,attr"`
+	Type    string   `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	XMLName xml.Name      `xml:"node"`
+	ID      string        `xml:"id,attr"`
+	Data    []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	XMLName xml.Name      `xml:"edge"`
+	Source  string        `xml:"source,attr"`
+	Target  string        `xml:"target,attr"`
+	Data    []graphMLData `xml:"data"`
+}
+
+var (
+	graphMLRoot = xml.StartElement{
+		Name: xml.Name{Local: "graphml"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "http://graphml.graphdrawing.org/xmlns"}},
+	}
+	graphMLGraph = xml.StartElement{
+		Name: xml.Name{Local: "graph"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "id"}, Value: "vk"},
+			{Name: xml.Name{Local: "edgedefault"}, Value: "directed"},
+		},
+	}
+)
+
+// graphMLWriter GraphML: узлы и связи пишутся по мере поступления, порядок не важен.
+type graphMLWriter struct {
+	enc *xml.Encoder
+}
+
+func newGraphMLWriter(w io.Writer) (*graphMLWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.EncodeToken(graphMLRoot); err != nil {
+		return nil, err
+	}
+
+	keys := []graphMLKey{
+		{ID: "label", For: "node", Name: "label", Type: "string"},
+		{ID: "vk_id", For: "node", Name: "vk_id", Type: "long"},
+	}
+	for _, attr := range nodeAttributes {
+		keys = append(keys, graphMLKey{ID: attr.name, For: "node", Name: attr.name, Type: attr.kind})
+	}
+	keys = append(keys, graphMLKey{ID: "type", For: "edge", Name: "type", Type: "string"})
+
+	for _, key := range keys {
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.EncodeToken(graphMLGraph); err != nil {
+		return nil, err
+	}
+
+	return &graphMLWriter{enc: enc}, nil
+}
+
+func (w *graphMLWriter) WriteNode(node models.Node) error {
+	data := []graphMLData{
+		{Key: "label", Value: node.Label},
+		{Key: "vk_id", Value: strconv.FormatUint(node.ID, 10)},
+	}
+	for _, attr := range nodeAttributes {
+		if value, ok := property(node, attr.name); ok {
+			data = append(data, graphMLData{Key: attr.name, Value: value})
+		}
+	}
+
+	return w.enc.Encode(graphMLNode{ID: nodeKey(node.Label, node.ID), Data: data})
+}
+
+func (w *graphMLWriter) WriteRelationship(rel models.Relationship) error {
+	return w.enc.Encode(graphMLEdge{
+		Source: nodeKey(models.LabelUser, rel.SourceID),
+		Target: nodeKey(rel.TargetLabel, rel.TargetID),
+		Data:   []graphMLData{{Key: "type", Value: rel.Type}},
+	})
+}
+
+func (w *graphMLWriter) Close() error {
+	if err := w.enc.EncodeToken(graphMLGraph.End()); err != nil {
+		return err
+	}
+
+	if err := w.enc.EncodeToken(graphMLRoot.End()); err != nil {
+		return err
+	}
+
+	return w.enc.Close()
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+// graphBuilder Собирает узлы и связи без повторов. Повторно встреченный узел заменяет свойства,
+// как и последовательные MERGE ... SET.
+type graphBuilder struct {
+	graph         models.Graph
+	users         map[uint64]int
+	groups        map[uint64]int
+	relationships map[models.Relationship]struct{}
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{
+		users:         make(map[uint64]int),
+		groups:        make(map[uint64]int),
+		relationships: make(map[models.Relationship]struct{}),
+	}
+}
+
+// addUserTree Добавляет пользователя, его подписчиков и подписки рекурсивно. Узлы без id пропускаются.
+func (b *graphBuilder) addUserTree(user models.User) {
+	if user.ID == 0 {
+		return
+	}
+
+	b.addUser(user)
+
+	for _, follower := range user.Followers {
+		if follower.ID == 0 {
+			continue
+		}
+
+		b.addUserTree(follower)
+		b.addRelationship(models.RelationshipFollow, follower.ID, user.ID, models.LabelUser)
+	}
+
+	for _, subscription := range user.Subscriptions.Users {
+		if subscription.ID == 0 {
+			continue
+		}
+
+		b.addUserTree(subscription)
+		b.addRelationship(models.RelationshipSubscribe, user.ID, subscription.ID, models.LabelUser)
+	}
+
+	for _, group := range user.Subscriptions.Groups {
+		if group.ID == 0 {
+			continue
+		}
+
+		b.addGroup(group)
+		b.addRelationship(models.RelationshipSubscribe, user.ID, group.ID, models.LabelGroup)
+	}
+}
+
+// addUser, addGroup и addRelationship возвращают false, если узел или связь уже были.
+func (b *graphBuilder) addUser(user models.User) bool {
+	user.Followers = nil
+	user.Subscriptions = models.Subscriptions{}
+
+	if i, ok := b.users[user.ID]; ok {
+		b.graph.Users[i] = user
+		return false
+	}
+
+	b.users[user.ID] = len(b.graph.Users)
+	b.graph.Users = append(b.graph.Users, user)
+	return true
+}
+
+func (b *graphBuilder) addGroup(group models.Group) bool {
+	if i, ok := b.groups[group.ID]; ok {
+		b.graph.Groups[i] = group
+		return false
+	}
+
+	b.groups[group.ID] = len(b.graph.Groups)
+	b.graph.Groups = append(b.graph.Groups, group)
+	return true
+}
+
+func (b *graphBuilder) addRelationship(relType string, sourceID, targetID uint64, targetLabel string) bool {
+	rel := models.Relationship{
+		Type:        relType,
+		SourceID:    sourceID,
+		TargetID:    targetID,
+		TargetLabel: targetLabel,
+	}
+
+	if _, ok := b.relationships[rel]; ok {
+		return false
+	}
+
+	b.relationships[rel] = struct{}{}
+	b.graph.Relationships = append(b.graph.Relationships, rel)
+	return true
+}
+
+// addStubs Отмечает концы связей, которых нет среди узлов графа.
+func (b *graphBuilder) addStubs() {
+	stubUsers := make(map[uint64]struct{})
+	stubGroups := make(map[uint64]struct{})
+
+	stub := func(index map[uint64]int, stubs map[uint64]struct{}, list *[]uint64, id uint64) {
+		if _, ok := index[id]; ok {
+			return
+		}
+		if _, ok := stubs[id]; ok {
+			return
+		}
+
+		stubs[id] = struct{}{}
+		*list = append(*list, id)
+	}
+
+	for _, rel := range b.graph.Relationships {
+		stub(b.users, stubUsers, &b.graph.StubUsers, rel.SourceID)
+		if rel.TargetLabel == models.LabelGroup {
+			stub(b.groups, stubGroups, &b.graph.StubGroups, rel.TargetID)
+		} else {
+			stub(b.users, stubUsers, &b.graph.StubUsers, rel.TargetID)
+		}
+	}
+}
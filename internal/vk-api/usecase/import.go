@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/importer"
+	"github.com/rs/zerolog/log"
+	"slices"
+)
+
+var (
+	ErrInvalidImport = errors.New("invalid import")
+	// ErrImportInterrupted Запись импорта прервалась, часть графа уже в хранилище.
+	ErrImportInterrupted = errors.New("import interrupted")
+)
+
+const (
+	// MaxImportErrors Причин пропуска в отчете не больше, остальные пропущенные записи только считаются.
+	MaxImportErrors = 100
+	// ImportBatchSize Число узлов или связей, записываемых в хранилище за один вызов.
+	ImportBatchSize = 1000
+)
+
+// ImportGraph Загружает выгрузки JSON и списки связей CSV в хранилище. Узлы объединяются по VK id,
+// записи с ошибками пропускаются и попадают в отчет, а файл, который не удается дочитать, отменяет
+// весь импорт. При dryRun граф только собирается и проверяется.
+//
+// Граф записывается пачками: пользователи, группы, узлы без свойств, затем связи. Если запись прервалась,
+// возвращается ErrImportInterrupted вместе с отчетом о том, что уже записано.
+func (uc *UserUsecase) ImportGraph(ctx context.Context, files []importer.File, dryRun bool) (models.ImportReport, error) {
+	imp := &graphImport{
+		builder: newGraphBuilder(),
+		report:  models.ImportReport{DryRun: dryRun, Files: len(files)},
+	}
+
+	for _, file := range files {
+		format, err := importer.Format(file.Name, file.Format)
+		if err != nil {
+			return models.ImportReport{}, fmt.Errorf("%w: %s: %w", ErrInvalidImport, file.Name, err)
+		}
+
+		records, err := importer.Read(format, file.Reader)
+		if err != nil {
+			return models.ImportReport{}, fmt.Errorf("%w: %s: %w", ErrInvalidImport, file.Name, err)
+		}
+
+		imp.add(file.Name, records)
+	}
+
+	b := imp.builder
+	b.addStubs()
+
+	report := imp.report
+	report.Users = len(b.graph.Users)
+	report.Groups = len(b.graph.Groups)
+	report.Relationships = len(b.graph.Relationships)
+	report.Stubs = len(b.graph.StubUsers) + len(b.graph.StubGroups)
+
+	if dryRun || report.Users+report.Groups+report.Relationships == 0 {
+		return report, nil
+	}
+
+	if err := uc.writeImport(ctx, b.graph, &report.Written); err != nil {
+		report.Error = err.Error()
+		return report, fmt.Errorf("%w: %w", ErrImportInterrupted, err)
+	}
+
+	return report, nil
+}
+
+// writeImport Записывает граф пачками по ImportBatchSize и учитывает записанное в written.
+func (uc *UserUsecase) writeImport(ctx context.Context, graph models.Graph, written *models.ImportProgress) error {
+	log.Info().Msgf("Импорт графа: пользователей %d, групп %d, узлов без свойств %d, связей %d",
+		len(graph.Users), len(graph.Groups), len(graph.StubUsers)+len(graph.StubGroups), len(graph.Relationships))
+
+	if err := writeImportBatches(ctx, graph.Users, &written.Users, uc.repo.CreateUsers); err != nil {
+		return fmt.Errorf("uc.repo.CreateUsers: %w", err)
+	}
+
+	if err := writeImportBatches(ctx, graph.Groups, &written.Groups, uc.repo.CreateGroups); err != nil {
+		return fmt.Errorf("uc.repo.CreateGroups: %w", err)
+	}
+
+	if err := writeImportBatches(ctx, graph.StubUsers, &written.Stubs, uc.createStubNodes(models.LabelUser)); err != nil {
+		return fmt.Errorf("uc.repo.CreateStubNodes: %w", err)
+	}
+
+	if err := writeImportBatches(ctx, graph.StubGroups, &written.Stubs, uc.createStubNodes(models.LabelGroup)); err != nil {
+		return fmt.Errorf("uc.repo.CreateStubNodes: %w", err)
+	}
+
+	if err := writeImportBatches(ctx, graph.Relationships, &written.Relationships, uc.repo.CreateRelationships); err != nil {
+		return fmt.Errorf("uc.repo.CreateRelationships: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *UserUsecase) createStubNodes(label string) func(context.Context, []uint64) error {
+	return func(ctx context.Context, ids []uint64) error {
+		return uc.repo.CreateStubNodes(ctx, label, ids)
+	}
+}
+
+// writeImportBatches Передает items в write пачками и прибавляет к written размер каждой записанной пачки.
+func writeImportBatches[T any](ctx context.Context, items []T, written *int, write func(context.Context, []T) error) error {
+	for batch := range slices.Chunk(items, ImportBatchSize) {
+		if err := write(ctx, batch); err != nil {
+			return err
+		}
+
+		*written += len(batch)
+	}
+
+	return nil
+}
+
+// graphImport Собирает граф из записей импорта и отчет о них.
+type graphImport struct {
+	builder *graphBuilder
+	report  models.ImportReport
+}
+
+func (imp *graphImport) add(file string, records importer.Records) {
+	for _, err := range records.Errors {
+		imp.skip(file, err)
+	}
+
+	for _, record := range records.Users {
+		imp.addUserTree(file, record.Position, record.User)
+	}
+
+	for _, record := range records.Relationships {
+		rel := record.Relationship
+		if err := checkRelationship(rel); err != nil {
+			imp.skip(file, fmt.Errorf("%s: %w", record.Position, err))
+			continue
+		}
+
+		imp.count(imp.builder.addRelationship(rel.Type, rel.SourceID, rel.TargetID, rel.TargetLabel))
+	}
+}
+
+// addUserTree Как graphBuilder.addUserTree, но узел с ошибкой пропускается вместе с вложенными в него
+// и попадает в отчет. Возвращает false, если пользователь пропущен.
+func (imp *graphImport) addUserTree(file, position string, user models.User) bool {
+	if err := checkImportUser(user); err != nil {
+		imp.skip(file, fmt.Errorf("%s: %w", position, err))
+		return false
+	}
+
+	imp.count(imp.builder.addUser(imp.mergeUser(user)))
+
+	for i, follower := range user.Followers {
+		if imp.addUserTree(file, fmt.Sprintf("%s.followers[%d]", position, i), follower) {
+			imp.count(imp.builder.addRelationship(models.RelationshipFollow, follower.ID, user.ID, models.LabelUser))
+		}
+	}
+
+	for i, subscription := range user.Subscriptions.Users {
+		if imp.addUserTree(file, fmt.Sprintf("%s.subscriptions.users[%d]", position, i), subscription) {
+			imp.count(imp.builder.addRelationship(models.RelationshipSubscribe, user.ID, subscription.ID, models.LabelUser))
+		}
+	}
+
+	for i, group := range user.Subscriptions.Groups {
+		if group.ID == 0 {
+			imp.skip(file, fmt.Errorf("%s.subscriptions.groups[%d]: group id is required", position, i))
+			continue
+		}
+
+		imp.count(imp.builder.addGroup(imp.mergeGroup(group)))
+		imp.count(imp.builder.addRelationship(models.RelationshipSubscribe, user.ID, group.ID, models.LabelGroup))
+	}
+
+	return true
+}
+
+// mergeUser Дополняет пустые свойства повтора значениями уже собранного пользователя: в выгрузке
+// один и тот же пользователь может встретиться и полностью, и только с id.
+func (imp *graphImport) mergeUser(user models.User) models.User {
+	i, ok := imp.builder.users[user.ID]
+	if !ok {
+		return user
+	}
+
+	prev := imp.builder.graph.Users[i]
+	user.ScreenName = cmp.Or(user.ScreenName, prev.ScreenName)
+	user.FirstName = cmp.Or(user.FirstName, prev.FirstName)
+	user.LastName = cmp.Or(user.LastName, prev.LastName)
+	user.Sex = cmp.Or(user.Sex, prev.Sex)
+	user.City.Title = cmp.Or(user.City.Title, prev.City.Title)
+
+	return user
+}
+
+func (imp *graphImport) mergeGroup(group models.Group) models.Group {
+	i, ok := imp.builder.groups[group.ID]
+	if !ok {
+		return group
+	}
+
+	prev := imp.builder.graph.Groups[i]
+	group.Name = cmp.Or(group.Name, prev.Name)
+	group.ScreenName = cmp.Or(group.ScreenName, prev.ScreenName)
+
+	return group
+}
+
+func checkImportUser(user models.User) error {
+	if user.ID == 0 {
+		return errors.New("user id is required")
+	}
+
+	if user.Sex > 2 {
+		return fmt.Errorf("user %d: sex must be 0, 1 or 2", user.ID)
+	}
+
+	return nil
+}
+
+// count Учитывает повтор, если узел или связь уже были.
+func (imp *graphImport) count(added bool) {
+	if !added {
+		imp.report.Duplicates++
+	}
+}
+
+func (imp *graphImport) skip(file string, err error) {
+	imp.report.Invalid++
+	if len(imp.report.Errors) < MaxImportErrors {
+		imp.report.Errors = append(imp.report.Errors, file+": "+err.Error())
+	}
+}
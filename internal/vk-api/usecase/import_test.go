@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/importer"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const (
+	importDump = `{
+		"id": 1,
+		"first_name": "Root",
+		"followers": [
+			{"id": 2, "followers": [{"id": 1}]},
+			{"id": 0, "followers": [{"id": 7}]},
+			{"id": 3, "sex": 5}
+		],
+		"subscriptions": {"users": [{"id": 2}], "groups": [{"id": 100, "name": "Group"}, {"id": 0}]}
+	}`
+	importEdges = "source,target,type\n4,1,Follow\n2,g100,Subscribe\n4,g200,Follow\n"
+)
+
+func importFiles() []importer.File {
+	return []importer.File{
+		{Name: "dump.json", Reader: strings.NewReader(importDump)},
+		{Name: "edges.csv", Reader: strings.NewReader(importEdges)},
+	}
+}
+
+func TestImportGraph(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserMemoryRepo()
+	uc := NewUserUsecase(nil, repo, nil)
+
+	expected := models.ImportReport{
+		DryRun:        true,
+		Files:         2,
+		Users:         2,
+		Groups:        1,
+		Relationships: 6,
+		Stubs:         1,
+		Duplicates:    2,
+		Invalid:       4,
+		Errors: []string{
+			"dump.json: record 1.followers[1]: user id is required",
+			"dump.json: record 1.followers[2]: user 3: sex must be 0, 1 or 2",
+			"dump.json: record 1.subscriptions.groups[1]: group id is required",
+			"edges.csv: line 4: invalid relationship: (:User)-[:Follow]->(:Group)",
+		},
+	}
+
+	report, err := uc.ImportGraph(ctx, importFiles(), true)
+	require.NoError(t, err)
+	require.Equal(t, expected, report)
+
+	users, err := repo.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Zero(t, users)
+
+	expected.DryRun = false
+	expected.Written = models.ImportProgress{Users: 2, Groups: 1, Stubs: 1, Relationships: 6}
+	report, err = uc.ImportGraph(ctx, importFiles(), false)
+	require.NoError(t, err)
+	require.Equal(t, expected, report)
+
+	users, err = repo.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, users)
+
+	followers, err := uc.ListFollowers(ctx, 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.User{{ID: 2}, {ID: 4}}, followers)
+
+	root, err := uc.FindUser(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Root", root.FirstName)
+}
+
+func TestImportGraphInvalidFile(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserMemoryRepo()
+	uc := NewUserUsecase(nil, repo, nil)
+
+	_, err := uc.ImportGraph(ctx, []importer.File{
+		{Name: "dump.json", Reader: strings.NewReader(importDump)},
+		{Name: "broken.json", Reader: strings.NewReader(`{"id": `)},
+	}, false)
+	require.ErrorIs(t, err, ErrInvalidImport)
+	require.ErrorIs(t, err, importer.ErrInvalidFile)
+
+	_, err = uc.ImportGraph(ctx, []importer.File{{Name: "graph.gexf", Reader: strings.NewReader("")}}, false)
+	require.ErrorIs(t, err, importer.ErrUnknownFormat)
+
+	users, err := repo.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Zero(t, users)
+}
+
+// failingRelationshipsRepo Хранилище, в котором запись связей всегда завершается ошибкой.
+type failingRelationshipsRepo struct {
+	*memory.UserMemoryRepo
+}
+
+func (failingRelationshipsRepo) CreateRelationships(context.Context, []models.Relationship) error {
+	return errors.New("connection lost")
+}
+
+func TestImportGraphInterrupted(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserMemoryRepo()
+	uc := NewUserUsecase(nil, failingRelationshipsRepo{repo}, nil)
+
+	report, err := uc.ImportGraph(ctx, importFiles(), false)
+	require.ErrorIs(t, err, ErrImportInterrupted)
+	require.Equal(t, models.ImportProgress{Users: 2, Groups: 1, Stubs: 1}, report.Written)
+	require.Equal(t, "uc.repo.CreateRelationships: connection lost", report.Error)
+
+	// Узлы из записанных пачек остаются в хранилище.
+	users, err := repo.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, users)
+}
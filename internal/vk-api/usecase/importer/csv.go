@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// csvColumns Номера колонок source, target и type, -1 - колонки нет.
+type csvColumns struct {
+	source, target, relType int
+}
+
+// positionalColumns Колонки файла без заголовка.
+var positionalColumns = csvColumns{source: 0, target: 1, relType: 2}
+
+// readCSV Список связей. Первая строка считается заголовком, если в ней есть колонка source, тогда колонки
+// ищутся по именам source, target и type, остальные пропускаются. Без заголовка колонки идут в этом порядке.
+//
+// Узел задается VK id пользователя или ключом выгрузки: u<id> - пользователь, g<id> - группа. Источником
+// связи может быть только пользователь. Без type связь с группой - Subscribe, с пользователем - Follow.
+func readCSV(r io.Reader) (Records, error) {
+	var records Records
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	columns := positionalColumns
+	for first := true; ; first = false {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return Records{}, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		position := "line " + strconv.Itoa(line)
+
+		if first && slices.ContainsFunc(row, isSourceColumn) {
+			if columns, err = headerColumns(row); err != nil {
+				return Records{}, fmt.Errorf("%w: %s: %w", ErrInvalidFile, position, err)
+			}
+			continue
+		}
+
+		rel, err := csvRelationship(row, columns)
+		if err != nil {
+			records.Errors = append(records.Errors, &RecordError{Position: position, Err: err})
+			continue
+		}
+
+		records.Relationships = append(records.Relationships, RelationshipRecord{Position: position, Relationship: rel})
+	}
+}
+
+func isSourceColumn(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), "source")
+}
+
+func headerColumns(header []string) (csvColumns, error) {
+	columns := csvColumns{source: -1, target: -1, relType: -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "source":
+			columns.source = i
+		case "target":
+			columns.target = i
+		case "type":
+			columns.relType = i
+		}
+	}
+
+	if columns.target < 0 {
+		return csvColumns{}, errors.New("header has no target column")
+	}
+
+	return columns, nil
+}
+
+func csvRelationship(row []string, columns csvColumns) (models.Relationship, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+
+	if len(row) <= max(columns.source, columns.target) {
+		return models.Relationship{}, fmt.Errorf("expected source and target, got %d fields", len(row))
+	}
+
+	sourceLabel, sourceID, err := nodeKey(field(columns.source))
+	if err != nil {
+		return models.Relationship{}, fmt.Errorf("source: %w", err)
+	}
+	if sourceLabel != models.LabelUser {
+		return models.Relationship{}, errors.New("source must be a user")
+	}
+
+	targetLabel, targetID, err := nodeKey(field(columns.target))
+	if err != nil {
+		return models.Relationship{}, fmt.Errorf("target: %w", err)
+	}
+
+	return models.Relationship{
+		Type:        relationshipType(field(columns.relType), targetLabel),
+		SourceID:    sourceID,
+		TargetID:    targetID,
+		TargetLabel: targetLabel,
+	}, nil
+}
+
+// nodeKey Метка и VK id узла из 123, u123 или g123.
+func nodeKey(key string) (string, uint64, error) {
+	label, idStr := models.LabelUser, key
+	switch {
+	case strings.HasPrefix(key, "u"):
+		idStr = key[1:]
+	case strings.HasPrefix(key, "g"):
+		label, idStr = models.LabelGroup, key[1:]
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return "", 0, fmt.Errorf("invalid node %q", key)
+	}
+
+	return label, id, nil
+}
+
+// relationshipType Тип связи без учета регистра. Неизвестный тип возвращается как есть.
+func relationshipType(relType, targetLabel string) string {
+	switch {
+	case relType == "" && targetLabel == models.LabelGroup:
+		return models.RelationshipSubscribe
+	case relType == "":
+		return models.RelationshipFollow
+	case strings.EqualFold(relType, models.RelationshipFollow):
+		return models.RelationshipFollow
+	case strings.EqualFold(relType, models.RelationshipSubscribe):
+		return models.RelationshipSubscribe
+	}
+
+	return relType
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// FormatJSON Выгрузка models.User с вложенными подписчиками и подписками: один объект, массив
+	// или несколько объектов подряд.
+	FormatJSON = "json"
+	// FormatCSV Список связей source,target[,type].
+	FormatCSV = "csv"
+)
+
+// Formats Поддерживаемые форматы импорта.
+var Formats = []string{FormatJSON, FormatCSV}
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrInvalidFile Файл не удается разобрать дальше: битый JSON или CSV.
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// File Файл импорта. Пустой Format определяется по расширению Name.
+type File struct {
+	Name   string
+	Format string
+	Reader io.Reader
+}
+
+// UserRecord Пользователь из JSON вместе с вложенными подписчиками и подписками.
+type UserRecord struct {
+	// Position Номер записи в файле для отчета.
+	Position string
+	User     models.User
+}
+
+// RelationshipRecord Связь из строки CSV.
+type RelationshipRecord struct {
+	// Position Номер строки в файле для отчета.
+	Position     string
+	Relationship models.Relationship
+}
+
+// RecordError Запись, которую не удалось разобрать. Она пропускается, чтение файла продолжается.
+type RecordError struct {
+	Position string
+	Err      error
+}
+
+func (e *RecordError) Error() string {
+	return e.Position + ": " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Records Разобранный файл.
+type Records struct {
+	Users         []UserRecord
+	Relationships []RelationshipRecord
+	Errors        []*RecordError
+}
+
+// Format Формат файла: явный format или по расширению name.
+func Format(name, format string) (string, error) {
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	}
+
+	switch format {
+	case FormatJSON, FormatCSV:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("%w %q, use json or csv", ErrUnknownFormat, format)
+}
+
+// Read Разбирает файл. Ошибка возвращается, только если файл нельзя дочитать, ошибки отдельных
+// записей собираются в Records.Errors.
+func Read(format string, r io.Reader) (Records, error) {
+	switch format {
+	case FormatJSON:
+		return readJSON(r)
+	case FormatCSV:
+		return readCSV(r)
+	}
+
+	return Records{}, fmt.Errorf("%w %q, use json or csv", ErrUnknownFormat, format)
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func recordErrors(records Records) []string {
+	var errs []string
+	for _, err := range records.Errors {
+		errs = append(errs, err.Error())
+	}
+
+	return errs
+}
+
+func TestReadJSON(t *testing.T) {
+	records, err := Read(FormatJSON, strings.NewReader(`{
+		"id": 1,
+		"first_name": "Root",
+		"followers": [{"id": 2, "city": {"title": "Surgut"}}],
+		"subscriptions": {"groups": [{"id": 100, "name": "Group"}]}
+	}
+	[{"id": 3}, {"id": "four"}, {"id": 5}]
+	{"id": 6}`))
+	require.NoError(t, err)
+
+	require.Len(t, records.Users, 4)
+	require.Equal(t, UserRecord{Position: "record 1", User: models.User{
+		ID:            1,
+		FirstName:     "Root",
+		Followers:     []models.User{{ID: 2, City: models.City{Title: "Surgut"}}},
+		Subscriptions: models.Subscriptions{Groups: []models.Group{{ID: 100, Name: "Group"}}},
+	}}, records.Users[0])
+	require.Equal(t, UserRecord{Position: "record 5", User: models.User{ID: 6}}, records.Users[3])
+
+	require.Len(t, records.Errors, 1)
+	require.Equal(t, "record 3", records.Errors[0].Position)
+
+	_, err = Read(FormatJSON, strings.NewReader(`{"id": 1} {"id": `))
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestReadCSV(t *testing.T) {
+	records, err := Read(FormatCSV, strings.NewReader(`weight,Target,source,type
+1,u1,2,Follow
+1,g100,u2,
+1,3,1,subscribe
+1,1,g100,Follow
+1,x,2,Follow
+1,5,6,Likes
+1
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, []RelationshipRecord{
+		{"line 2", models.Relationship{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser}},
+		{"line 3", models.Relationship{Type: models.RelationshipSubscribe, SourceID: 2, TargetID: 100, TargetLabel: models.LabelGroup}},
+		{"line 4", models.Relationship{Type: models.RelationshipSubscribe, SourceID: 1, TargetID: 3, TargetLabel: models.LabelUser}},
+		{"line 7", models.Relationship{Type: "Likes", SourceID: 6, TargetID: 5, TargetLabel: models.LabelUser}},
+	}, records.Relationships)
+	require.Equal(t, []string{
+		"line 5: source must be a user",
+		`line 6: target: invalid node "x"`,
+		"line 8: expected source and target, got 1 fields",
+	}, recordErrors(records))
+
+	records, err = Read(FormatCSV, strings.NewReader("2,1\n3,g100\n"))
+	require.NoError(t, err)
+	require.Equal(t, []RelationshipRecord{
+		{"line 1", models.Relationship{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser}},
+		{"line 2", models.Relationship{Type: models.RelationshipSubscribe, SourceID: 3, TargetID: 100, TargetLabel: models.LabelGroup}},
+	}, records.Relationships)
+
+	_, err = Read(FormatCSV, strings.NewReader("source,id\n1,2\n"))
+	require.ErrorIs(t, err, ErrInvalidFile)
+
+	_, err = Read(FormatCSV, strings.NewReader("1,\"2\n"))
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestFormat(t *testing.T) {
+	format, err := Format("dump.JSON", "")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, format)
+
+	format, err = Format("edges.txt", FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	_, err = Format("graph.gexf", "")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"io"
+	"strconv"
+)
+
+// readJSON Записи идут объектами верхнего уровня или элементами массивов. Запись с полем неверного типа
+// пропускается целиком, синтаксическая ошибка прерывает чтение файла.
+func readJSON(r io.Reader) (Records, error) {
+	var records Records
+	dec := json.NewDecoder(r)
+	n := 0
+
+	add := func(raw json.RawMessage) {
+		n++
+		position := "record " + strconv.Itoa(n)
+
+		var user models.User
+		if err := json.Unmarshal(raw, &user); err != nil {
+			records.Errors = append(records.Errors, &RecordError{Position: position, Err: err})
+			return
+		}
+
+		records.Users = append(records.Users, UserRecord{Position: position, User: user})
+	}
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+
+			return Records{}, fmt.Errorf("%w: after record %d: %w", ErrInvalidFile, n, err)
+		}
+
+		if !bytes.HasPrefix(raw, []byte("[")) {
+			add(raw)
+			continue
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return Records{}, fmt.Errorf("%w: after record %d: %w", ErrInvalidFile, n, err)
+		}
+
+		for _, item := range items {
+			add(item)
+		}
+	}
+}
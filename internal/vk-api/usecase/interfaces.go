@@ -1 +1,82 @@
 package usecase
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
+)
+
+// VKAPI Клиент VK API. Реализуется rest.VKClient.
+type VKAPI interface {
+	TokensStatus() []rest.TokenStatus
+
+	GetUsers(ctx context.Context, ids ...uint64) ([]models.User, error)
+	GetUsersByScreenNames(ctx context.Context, screenNames ...string) ([]models.User, error)
+	GetFollowers(ctx context.Context, id uint64) (followers []models.User, total uint64, err error)
+	GetSubscriptions(ctx context.Context, id uint64) (subscriptions models.Subscriptions, total uint64, err error)
+	GetUsersWithConnections(ctx context.Context, ids ...uint64) ([]rest.UserConnections, error)
+}
+
+// GraphRepository Хранилище графа пользователей и групп VK.
+type GraphRepository interface {
+	CreateIndexes(ctx context.Context) error
+
+	CreateUser(ctx context.Context, user models.User) error
+	CreateGroup(ctx context.Context, group models.Group) error
+	CreateFollowRelationship(ctx context.Context, follower models.User, followee models.User) error
+	CreateSubscribeUserUserRelationship(ctx context.Context, subscriber models.User, subscribed models.User) error
+	CreateSubscribeUserGroupRelationship(ctx context.Context, user models.User, group models.Group) error
+
+	// SaveGraph Записывает узлы и связи атомарно.
+	SaveGraph(ctx context.Context, graph models.Graph) error
+
+	// CreateUsers, CreateGroups, CreateStubNodes и CreateRelationships Записывают пачками, каждую в отдельной
+	// транзакции: при ошибке уже записанные пачки остаются. Связи с отсутствующими узлами пропускаются.
+	CreateUsers(ctx context.Context, users []models.User) error
+	CreateGroups(ctx context.Context, groups []models.Group) error
+	// CreateStubNodes Создает узлы label только с VK id, существующие узлы не меняются.
+	CreateStubNodes(ctx context.Context, label string, ids []uint64) error
+	CreateRelationships(ctx context.Context, relationships []models.Relationship) error
+
+	GetUsersCount(ctx context.Context) (int, error)
+	GetGroupsCount(ctx context.Context) (int, error)
+	GetTopUsersByFollowersCount(ctx context.Context, limit int) ([]models.TopUser, error)
+	GetTopGroupsBySubscribersCount(ctx context.Context, limit int) ([]models.TopGroup, error)
+	GetUsersWithDifferentGroups(ctx context.Context, limit int) ([]models.User, error)
+
+	// GetNodes Возвращает не больше query.Limit узлов и позицию последнего из них, если есть следующая страница.
+	GetNodes(ctx context.Context, query models.NodeQuery) ([]models.Node, *models.NodeCursor, error)
+	GetNodeWithRelationships(ctx context.Context, ref models.NodeRef) (interface{}, error)
+	DeleteNode(ctx context.Context, ref models.NodeRef) error
+
+	GetUsers(ctx context.Context) ([]models.User, error)
+	GetGroups(ctx context.Context) ([]models.Group, error)
+	// FindUser и FindGroup возвращают nil, если узла нет.
+	FindUser(ctx context.Context, id uint64) (*models.User, error)
+	FindGroup(ctx context.Context, id uint64) (*models.Group, error)
+	// UpdateUser и UpdateGroup перезаписывают свойства и возвращают false, если узла нет.
+	UpdateUser(ctx context.Context, user models.User) (bool, error)
+	UpdateGroup(ctx context.Context, group models.Group) (bool, error)
+	GetFollowers(ctx context.Context, userID uint64) ([]models.User, error)
+	GetSubscriptions(ctx context.Context, userID uint64) (models.Subscriptions, error)
+	GetSubscribers(ctx context.Context, groupID uint64) ([]models.User, error)
+
+	// GetMutualFollowers, GetMutualSubscriptions и GetCommonGroups возвращают узлы, связанные с каждым
+	// из различных userIDs, в порядке VK id и число узлов, связанных хотя бы с одним из них.
+	GetMutualFollowers(ctx context.Context, userIDs []uint64) ([]models.User, int, error)
+	GetMutualSubscriptions(ctx context.Context, userIDs []uint64) ([]models.User, int, error)
+	GetCommonGroups(ctx context.Context, userIDs []uint64) ([]models.Group, int, error)
+
+	GetRelationships(ctx context.Context, filter models.RelationshipFilter) ([]models.Relationship, error)
+	// DeleteRelationship Возвращает false, если связи нет.
+	DeleteRelationship(ctx context.Context, rel models.Relationship) (bool, error)
+
+	// FindShortestPaths Возвращает пустой список, если пути не длиннее query.MaxHops нет.
+	FindShortestPaths(ctx context.Context, query models.PathQuery) ([]models.Path, error)
+	// GetEgoNetwork Возвращает nil, если центрального узла нет.
+	GetEgoNetwork(ctx context.Context, query models.EgoQuery) (*models.Subgraph, error)
+	// ExportGraph Передает в node узлы по фильтру, затем в rel связи между ними. Ошибка обработчика
+	// прерывает выгрузку. UserNeo4jRepo передает их потоком, не собирая граф в памяти.
+	ExportGraph(ctx context.Context, filter models.ExportFilter,
+		node func(models.Node) error, rel func(models.Relationship) error) error
+}
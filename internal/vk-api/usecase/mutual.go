@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+const MaxMutualUsers = 50
+
+// MutualConnections Общие подписчики, подписки и группы пары или набора пользователей.
+func (uc *UserUsecase) MutualConnections(ctx context.Context, userIDs []uint64) (models.MutualConnections, error) {
+	userIDs, err := uc.checkMutualUsers(ctx, userIDs)
+	if err != nil {
+		return models.MutualConnections{}, err
+	}
+
+	followers, err := uc.mutualFollowers(ctx, userIDs)
+	if err != nil {
+		return models.MutualConnections{}, err
+	}
+
+	subscriptions, err := uc.mutualSubscriptions(ctx, userIDs)
+	if err != nil {
+		return models.MutualConnections{}, err
+	}
+
+	groups, err := uc.commonGroups(ctx, userIDs)
+	if err != nil {
+		return models.MutualConnections{}, err
+	}
+
+	return models.MutualConnections{
+		UserIDs:       userIDs,
+		Followers:     followers,
+		Subscriptions: subscriptions,
+		Groups:        groups,
+	}, nil
+}
+
+// MutualFollowers Пользователи, подписанные на каждого из userIDs.
+func (uc *UserUsecase) MutualFollowers(ctx context.Context, userIDs []uint64) (models.UserOverlap, error) {
+	userIDs, err := uc.checkMutualUsers(ctx, userIDs)
+	if err != nil {
+		return models.UserOverlap{}, err
+	}
+
+	return uc.mutualFollowers(ctx, userIDs)
+}
+
+// MutualSubscriptions Пользователи, на которых подписан каждый из userIDs.
+func (uc *UserUsecase) MutualSubscriptions(ctx context.Context, userIDs []uint64) (models.UserOverlap, error) {
+	userIDs, err := uc.checkMutualUsers(ctx, userIDs)
+	if err != nil {
+		return models.UserOverlap{}, err
+	}
+
+	return uc.mutualSubscriptions(ctx, userIDs)
+}
+
+// CommonGroups Группы, на которые подписан каждый из userIDs.
+func (uc *UserUsecase) CommonGroups(ctx context.Context, userIDs []uint64) (models.GroupOverlap, error) {
+	userIDs, err := uc.checkMutualUsers(ctx, userIDs)
+	if err != nil {
+		return models.GroupOverlap{}, err
+	}
+
+	return uc.commonGroups(ctx, userIDs)
+}
+
+func (uc *UserUsecase) mutualFollowers(ctx context.Context, userIDs []uint64) (models.UserOverlap, error) {
+	users, union, err := uc.repo.GetMutualFollowers(ctx, userIDs)
+	if err != nil {
+		return models.UserOverlap{}, fmt.Errorf("uc.repo.GetMutualFollowers: %w", err)
+	}
+
+	return models.UserOverlap{Count: len(users), Union: union, Jaccard: jaccard(len(users), union), Users: nonNil(users)}, nil
+}
+
+func (uc *UserUsecase) mutualSubscriptions(ctx context.Context, userIDs []uint64) (models.UserOverlap, error) {
+	users, union, err := uc.repo.GetMutualSubscriptions(ctx, userIDs)
+	if err != nil {
+		return models.UserOverlap{}, fmt.Errorf("uc.repo.GetMutualSubscriptions: %w", err)
+	}
+
+	return models.UserOverlap{Count: len(users), Union: union, Jaccard: jaccard(len(users), union), Users: nonNil(users)}, nil
+}
+
+func (uc *UserUsecase) commonGroups(ctx context.Context, userIDs []uint64) (models.GroupOverlap, error) {
+	groups, union, err := uc.repo.GetCommonGroups(ctx, userIDs)
+	if err != nil {
+		return models.GroupOverlap{}, fmt.Errorf("uc.repo.GetCommonGroups: %w", err)
+	}
+
+	return models.GroupOverlap{Count: len(groups), Union: union, Jaccard: jaccard(len(groups), union), Groups: nonNil(groups)}, nil
+}
+
+// checkMutualUsers Упорядочивает и убирает повторы. Нужно от 2 до MaxMutualUsers существующих пользователей.
+func (uc *UserUsecase) checkMutualUsers(ctx context.Context, userIDs []uint64) ([]uint64, error) {
+	userIDs = slices.Compact(slices.Sorted(slices.Values(userIDs)))
+
+	if len(userIDs) < 2 || len(userIDs) > MaxMutualUsers {
+		return nil, fmt.Errorf("%w: need between 2 and %d different user ids", ErrInvalidQuery, MaxMutualUsers)
+	}
+
+	if userIDs[0] == 0 {
+		return nil, fmt.Errorf("%w: user id must be positive", ErrInvalidID)
+	}
+
+	for _, id := range userIDs {
+		if _, err := uc.FindUser(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return userIDs, nil
+}
+
+// jaccard Мера Жаккара |A ∩ B| / |A ∪ B|, 0 для пустого объединения.
+func jaccard(intersection, union int) float64 {
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMutualConnections(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserMemoryRepo()
+	uc := NewUserUsecase(nil, repo, nil)
+
+	group := models.Group{ID: 100, Name: "Common"}
+	require.NoError(t, uc.SaveUser(ctx, models.User{
+		ID:        1,
+		Followers: []models.User{{ID: 3}, {ID: 4}},
+		Subscriptions: models.Subscriptions{
+			Users:  []models.User{{ID: 4}},
+			Groups: []models.Group{group},
+		},
+	}))
+	require.NoError(t, uc.SaveUser(ctx, models.User{
+		ID:        2,
+		Followers: []models.User{{ID: 3}, {ID: 5}},
+		Subscriptions: models.Subscriptions{
+			Users:  []models.User{{ID: 4}, {ID: 5}},
+			Groups: []models.Group{group, {ID: 101}},
+		},
+	}))
+
+	mutual, err := uc.MutualConnections(ctx, []uint64{2, 1, 2})
+	require.NoError(t, err)
+	require.Equal(t, models.MutualConnections{
+		UserIDs:       []uint64{1, 2},
+		Followers:     models.UserOverlap{Count: 1, Union: 3, Jaccard: 1.0 / 3, Users: []models.User{{ID: 3}}},
+		Subscriptions: models.UserOverlap{Count: 1, Union: 2, Jaccard: 0.5, Users: []models.User{{ID: 4}}},
+		Groups:        models.GroupOverlap{Count: 1, Union: 2, Jaccard: 0.5, Groups: []models.Group{group}},
+	}, mutual)
+
+	followers, err := uc.MutualFollowers(ctx, []uint64{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, models.UserOverlap{Count: 0, Union: 3, Jaccard: 0, Users: []models.User{}}, followers)
+
+	groups, err := uc.CommonGroups(ctx, []uint64{4, 5})
+	require.NoError(t, err)
+	require.Equal(t, models.GroupOverlap{Groups: []models.Group{}}, groups)
+
+	_, err = uc.MutualConnections(ctx, []uint64{1, 1})
+	require.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = uc.MutualConnections(ctx, []uint64{0, 1})
+	require.ErrorIs(t, err, ErrInvalidID)
+
+	_, err = uc.MutualSubscriptions(ctx, []uint64{1, 42})
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
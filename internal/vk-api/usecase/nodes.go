@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+const (
+	DefaultNodesLimit = 100
+	MaxNodesLimit     = 1000
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ListNodes Страница узлов. cursor - значение NextCursor предыдущей страницы с теми же фильтрами и сортировкой.
+func (uc *UserUsecase) ListNodes(ctx context.Context, query models.NodeQuery, cursor string) (models.NodePage, error) {
+	if err := checkNodeQuery(&query); err != nil {
+		return models.NodePage{}, err
+	}
+
+	if cursor != "" {
+		after, err := decodeNodeCursor(cursor)
+		if err != nil {
+			return models.NodePage{}, err
+		}
+
+		if after.Sort != query.Sort || after.Desc != query.Desc {
+			return models.NodePage{}, fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidQuery)
+		}
+
+		query.After = &after
+	}
+
+	nodes, next, err := uc.repo.GetNodes(ctx, query)
+	if err != nil {
+		return models.NodePage{}, fmt.Errorf("uc.repo.GetNodes: %w", err)
+	}
+
+	page := models.NodePage{Nodes: nodes}
+	if next != nil {
+		page.NextCursor = encodeNodeCursor(*next)
+	}
+
+	return page, nil
+}
+
+func checkNodeQuery(query *models.NodeQuery) error {
+	switch query.Label {
+	case "", models.LabelUser, models.LabelGroup:
+	default:
+		return fmt.Errorf("%w: unknown label %q, use User or Group", ErrInvalidQuery, query.Label)
+	}
+
+	switch query.Sort {
+	case "":
+		query.Sort = models.NodeSortID
+	case models.NodeSortID, models.NodeSortName:
+	default:
+		return fmt.Errorf("%w: unknown sort %q, use id or name", ErrInvalidQuery, query.Sort)
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultNodesLimit
+	case query.Limit < 0 || query.Limit > MaxNodesLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxNodesLimit)
+	}
+
+	if query.Sex != nil && *query.Sex > 2 {
+		return fmt.Errorf("%w: sex must be 0, 1 or 2", ErrInvalidQuery)
+	}
+
+	return nil
+}
+
+// encodeNodeCursor Курсор передается клиенту как непрозрачная строка.
+func encodeNodeCursor(cursor models.NodeCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeNodeCursor(s string) (models.NodeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.NodeCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	var cursor models.NodeCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return models.NodeCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	return cursor, nil
+}
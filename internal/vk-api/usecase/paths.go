@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+const (
+	DefaultPathMaxHops = 6
+	MaxPathMaxHops     = 10
+	DefaultPathsLimit  = 10
+	MaxPathsLimit      = 100
+)
+
+// FindPaths Кратчайшие пути между двумя пользователями. Отсутствие пути не ошибка: Paths пустой, Length 0.
+func (uc *UserUsecase) FindPaths(ctx context.Context, query models.PathQuery) (models.Paths, error) {
+	if err := checkPathQuery(&query); err != nil {
+		return models.Paths{}, err
+	}
+
+	// Несуществующий пользователь - 404, а не пустой список путей.
+	if _, err := uc.FindUser(ctx, query.SourceID); err != nil {
+		return models.Paths{}, err
+	}
+
+	if _, err := uc.FindUser(ctx, query.TargetID); err != nil {
+		return models.Paths{}, err
+	}
+
+	paths, err := uc.repo.FindShortestPaths(ctx, query)
+	if err != nil {
+		return models.Paths{}, fmt.Errorf("uc.repo.FindShortestPaths: %w", err)
+	}
+
+	result := models.Paths{SourceID: query.SourceID, TargetID: query.TargetID, Paths: nonNil(paths)}
+	if len(paths) > 0 {
+		result.Length = paths[0].Length
+	}
+
+	return result, nil
+}
+
+// checkPathQuery Проверяет запрос и заполняет умолчания: DefaultPathMaxHops, DefaultPathsLimit и умолчания обхода.
+func checkPathQuery(query *models.PathQuery) error {
+	if query.SourceID == 0 || query.TargetID == 0 {
+		return fmt.Errorf("%w: source_id and target_id are required", ErrInvalidQuery)
+	}
+
+	if query.SourceID == query.TargetID {
+		return fmt.Errorf("%w: source_id and target_id must differ", ErrInvalidQuery)
+	}
+
+	if err := checkTraversal(&query.Types, &query.Direction); err != nil {
+		return err
+	}
+
+	switch {
+	case query.MaxHops == 0:
+		query.MaxHops = DefaultPathMaxHops
+	case query.MaxHops < 0 || query.MaxHops > MaxPathMaxHops:
+		return fmt.Errorf("%w: max_hops must be between 1 and %d", ErrInvalidQuery, MaxPathMaxHops)
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultPathsLimit
+	case query.Limit < 0 || query.Limit > MaxPathsLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxPathsLimit)
+	}
+
+	return nil
+}
+
+// checkTraversal Проверяет типы связей и направление обхода. По умолчанию все типы и обход в обе стороны.
+func checkTraversal(types *[]string, direction *string) error {
+	if err := checkTypes(types); err != nil {
+		return err
+	}
+
+	switch *direction {
+	case "":
+		*direction = models.DirectionBoth
+	case models.DirectionOut, models.DirectionIn, models.DirectionBoth:
+	default:
+		return fmt.Errorf("%w: unknown direction %q, use out, in or both", ErrInvalidQuery, *direction)
+	}
+
+	return nil
+}
+
+// checkTypes Проверяет типы связей, упорядочивает и убирает повторы. По умолчанию все типы.
+func checkTypes(types *[]string) error {
+	if len(*types) == 0 {
+		*types = []string{models.RelationshipFollow, models.RelationshipSubscribe}
+	}
+
+	for _, relType := range *types {
+		if relType != models.RelationshipFollow && relType != models.RelationshipSubscribe {
+			return fmt.Errorf("%w: unknown relationship type %q, use Follow or Subscribe", ErrInvalidQuery, relType)
+		}
+	}
+
+	*types = slices.Compact(slices.Sorted(slices.Values(*types)))
+	return nil
+}
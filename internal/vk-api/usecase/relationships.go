@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+var (
+	ErrInvalidRelationship  = errors.New("invalid relationship")
+	ErrRelationshipNotFound = errors.New("relationship not found")
+)
+
+// checkRelationship Допустимы только (:User)-[:Follow]->(:User) и (:User)-[:Subscribe]->(:User|:Group).
+func checkRelationship(rel models.Relationship) error {
+	switch {
+	case rel.Type == models.RelationshipFollow && rel.TargetLabel == models.LabelUser,
+		rel.Type == models.RelationshipSubscribe && rel.TargetLabel == models.LabelUser,
+		rel.Type == models.RelationshipSubscribe && rel.TargetLabel == models.LabelGroup:
+	default:
+		return fmt.Errorf("%w: (:User)-[:%s]->(:%s)", ErrInvalidRelationship, rel.Type, rel.TargetLabel)
+	}
+
+	if rel.SourceID == 0 || rel.TargetID == 0 {
+		return fmt.Errorf("%w: source_id and target_id are required", ErrInvalidRelationship)
+	}
+
+	return nil
+}
+
+// CreateRelationship Создает связь между существующими узлами. Повторное создание связь не дублирует.
+func (uc *UserUsecase) CreateRelationship(ctx context.Context, rel models.Relationship) error {
+	if err := checkRelationship(rel); err != nil {
+		return err
+	}
+
+	source, err := uc.FindUser(ctx, rel.SourceID)
+	if err != nil {
+		return err
+	}
+
+	if rel.TargetLabel == models.LabelGroup {
+		target, err := uc.FindGroup(ctx, rel.TargetID)
+		if err != nil {
+			return err
+		}
+
+		return uc.repo.CreateSubscribeUserGroupRelationship(ctx, source, target)
+	}
+
+	target, err := uc.FindUser(ctx, rel.TargetID)
+	if err != nil {
+		return err
+	}
+
+	if rel.Type == models.RelationshipFollow {
+		return uc.repo.CreateFollowRelationship(ctx, source, target)
+	}
+
+	return uc.repo.CreateSubscribeUserUserRelationship(ctx, source, target)
+}
+
+// ListRelationships Связи по фильтру. Пустые поля фильтра не ограничивают выборку.
+func (uc *UserUsecase) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]models.Relationship, error) {
+	switch filter.Type {
+	case "", models.RelationshipFollow, models.RelationshipSubscribe:
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidRelationship, filter.Type)
+	}
+
+	switch filter.TargetLabel {
+	case "", models.LabelUser, models.LabelGroup:
+	default:
+		return nil, fmt.Errorf("%w: unknown target label %q", ErrInvalidRelationship, filter.TargetLabel)
+	}
+
+	return uc.repo.GetRelationships(ctx, filter)
+}
+
+// DeleteRelationship Удаляет одну связь, узлы остаются.
+func (uc *UserUsecase) DeleteRelationship(ctx context.Context, rel models.Relationship) error {
+	if err := checkRelationship(rel); err != nil {
+		return err
+	}
+
+	ok, err := uc.repo.DeleteRelationship(ctx, rel)
+	if err != nil {
+		return fmt.Errorf("uc.repo.DeleteRelationship: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: (%d)-[:%s]->(:%s %d)",
+			ErrRelationshipNotFound, rel.SourceID, rel.Type, rel.TargetLabel, rel.TargetID)
+	}
+
+	return nil
+}
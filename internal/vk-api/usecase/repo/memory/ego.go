@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// GetEgoNetwork Окрестность узла поиском в ширину и все связи запрошенных типов между ее узлами.
+func (r *UserMemoryRepo) GetEgoNetwork(_ context.Context, q models.EgoQuery) (*models.Subgraph, error) {
+	if err := validateTraversal(q.Types, q.Direction); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	center, err := r.lookup(q.Center)
+	if err != nil || center == nil {
+		return nil, err
+	}
+
+	adjacent := r.steps(q.Types, q.Direction, false)
+
+	subgraph := &models.Subgraph{}
+	seen := map[int64]struct{}{center.id: {}}
+	members := []*node{center}
+	frontier := []*node{center}
+	for hops := 1; hops <= q.Hops && len(frontier) > 0; hops++ {
+		var level []*node
+		for _, n := range frontier {
+			for _, s := range adjacent[n.id] {
+				if _, ok := seen[s.to]; ok {
+					continue
+				}
+
+				seen[s.to] = struct{}{}
+				level = append(level, r.nodes[s.to])
+			}
+		}
+
+		slices.SortFunc(level, func(a, b *node) int {
+			return cmp.Or(cmp.Compare(a.label, b.label), cmp.Compare(a.vkID(), b.vkID()))
+		})
+
+		if remaining := q.Limit - len(members); len(level) > remaining {
+			level = level[:remaining]
+			subgraph.Truncated = true
+		}
+
+		members = append(members, level...)
+		if subgraph.Truncated {
+			break
+		}
+
+		frontier = level
+	}
+
+	inside := make(map[int64]struct{}, len(members))
+	subgraph.Nodes = make([]models.Node, 0, len(members))
+	for _, n := range members {
+		inside[n.id] = struct{}{}
+		subgraph.Nodes = append(subgraph.Nodes, n.node())
+	}
+
+	subgraph.Relationships = make([]models.Relationship, 0)
+	for _, e := range r.edges {
+		_, from := inside[e.from]
+		_, to := inside[e.to]
+		if !from || !to || !slices.Contains(q.Types, e.relType) {
+			continue
+		}
+
+		subgraph.Relationships = append(subgraph.Relationships, r.relationship(e))
+	}
+	sortRelationships(subgraph.Relationships)
+
+	return subgraph, nil
+}
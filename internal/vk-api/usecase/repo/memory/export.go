@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// ExportGraph Узлы по фильтру в порядке метки и VK id, затем связи между ними в порядке GetRelationships.
+// В отличие от UserNeo4jRepo выгрузка сначала копируется целиком: обработчики вызываются после снятия
+// блокировки, чтобы медленный получатель не задерживал запись.
+func (r *UserMemoryRepo) ExportGraph(_ context.Context, filter models.ExportFilter,
+	node func(models.Node) error, rel func(models.Relationship) error) error {
+	switch filter.Label {
+	case "", models.LabelUser, models.LabelGroup:
+	default:
+		return fmt.Errorf("unsupported node label %q", filter.Label)
+	}
+
+	if err := validateTypes(filter.Types); err != nil {
+		return err
+	}
+
+	nodes, relationships := r.exportSnapshot(filter)
+
+	for _, n := range nodes {
+		if err := node(n); err != nil {
+			return err
+		}
+	}
+
+	for _, relationship := range relationships {
+		if err := rel(relationship); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepo) exportSnapshot(filter models.ExportFilter) ([]models.Node, []models.Relationship) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := models.NodeQuery{Label: filter.Label, City: filter.City, Sex: filter.Sex}
+
+	selected := make(map[int64]struct{})
+	var members []*node
+	for _, n := range r.nodes {
+		if n.matches(query) {
+			selected[n.id] = struct{}{}
+			members = append(members, n)
+		}
+	}
+
+	slices.SortFunc(members, func(a, b *node) int {
+		return cmp.Or(cmp.Compare(a.label, b.label), cmp.Compare(a.vkID(), b.vkID()))
+	})
+
+	nodes := make([]models.Node, 0, len(members))
+	for _, n := range members {
+		nodes = append(nodes, n.node())
+	}
+
+	var relationships []models.Relationship
+	for _, e := range r.edges {
+		_, from := selected[e.from]
+		_, to := selected[e.to]
+		if from && to && slices.Contains(filter.Types, e.relType) {
+			relationships = append(relationships, r.relationship(e))
+		}
+	}
+	sortRelationships(relationships)
+
+	return nodes, relationships
+}
@@ -0,0 +1,528 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type node struct {
+	id    int64
+	label string
+	user  models.User
+	group models.Group
+}
+
+type edge struct {
+	relType string
+	from    int64
+	to      int64
+}
+
+// UserMemoryRepo Граф пользователей и групп в памяти процесса.
+// Повторяет поведение UserNeo4jRepo и нужен для запуска сервиса и тестов без Neo4j.
+type UserMemoryRepo struct {
+	mu     sync.RWMutex
+	nextID int64
+	nodes  map[int64]*node
+	users  map[uint64]int64
+	groups map[uint64]int64
+	// edges Связи в порядке создания.
+	edges   []edge
+	edgeSet map[edge]struct{}
+}
+
+func NewUserMemoryRepo() *UserMemoryRepo {
+	return &UserMemoryRepo{
+		nodes:   make(map[int64]*node),
+		users:   make(map[uint64]int64),
+		groups:  make(map[uint64]int64),
+		edgeSet: make(map[edge]struct{}),
+	}
+}
+
+// CreateIndexes Уникальность VK id обеспечивается индексами users и groups.
+func (r *UserMemoryRepo) CreateIndexes(context.Context) error {
+	return nil
+}
+
+func (r *UserMemoryRepo) CreateUser(_ context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mergeUser(user)
+	return nil
+}
+
+func (r *UserMemoryRepo) mergeUser(user models.User) {
+	n := r.mergeNode(r.users, user.ID, models.LabelUser)
+	n.user = models.User{
+		ID:         user.ID,
+		ScreenName: user.ScreenName,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Sex:        user.Sex,
+		City:       user.City,
+	}
+}
+
+func (r *UserMemoryRepo) CreateGroup(_ context.Context, group models.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.mergeNode(r.groups, group.ID, models.LabelGroup)
+	n.group = group
+
+	return nil
+}
+
+func (r *UserMemoryRepo) mergeNode(index map[uint64]int64, vkID uint64, label string) *node {
+	if id, ok := index[vkID]; ok {
+		return r.nodes[id]
+	}
+
+	n := &node{id: r.nextID, label: label}
+	r.nextID++
+
+	r.nodes[n.id] = n
+	index[vkID] = n.id
+
+	return n
+}
+
+func (r *UserMemoryRepo) CreateFollowRelationship(_ context.Context, follower models.User, followee models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mergeEdge(models.RelationshipFollow, r.users, follower.ID, r.users, followee.ID)
+	return nil
+}
+
+func (r *UserMemoryRepo) CreateSubscribeUserUserRelationship(_ context.Context, subscriber models.User, subscribed models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mergeEdge(models.RelationshipSubscribe, r.users, subscriber.ID, r.users, subscribed.ID)
+	return nil
+}
+
+func (r *UserMemoryRepo) CreateSubscribeUserGroupRelationship(_ context.Context, user models.User, group models.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mergeEdge(models.RelationshipSubscribe, r.users, user.ID, r.groups, group.ID)
+	return nil
+}
+
+func (r *UserMemoryRepo) CreateUsers(ctx context.Context, users []models.User) error {
+	for _, user := range users {
+		if err := r.CreateUser(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepo) CreateGroups(ctx context.Context, groups []models.Group) error {
+	for _, group := range groups {
+		if err := r.CreateGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateStubNodes Создает узлы label только с VK id, существующие узлы не меняются.
+func (r *UserMemoryRepo) CreateStubNodes(_ context.Context, label string, ids []uint64) error {
+	if label != models.LabelUser && label != models.LabelGroup {
+		return fmt.Errorf("write stub nodes: unsupported node label %q", label)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		r.mergeStub(label, id)
+	}
+
+	return nil
+}
+
+// CreateRelationships Связи с отсутствующими узлами пропускаются.
+func (r *UserMemoryRepo) CreateRelationships(_ context.Context, relationships []models.Relationship) error {
+	if err := validateRelationships(relationships); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mergeRelationships(relationships)
+	return nil
+}
+
+// SaveGraph Записывает узлы и связи под одной блокировкой. Граф с недопустимой связью не записывается.
+func (r *UserMemoryRepo) SaveGraph(_ context.Context, graph models.Graph) error {
+	if err := validateRelationships(graph.Relationships); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range graph.Users {
+		r.mergeUser(user)
+	}
+
+	for _, group := range graph.Groups {
+		r.mergeNode(r.groups, group.ID, models.LabelGroup).group = group
+	}
+
+	for _, id := range graph.StubUsers {
+		r.mergeStub(models.LabelUser, id)
+	}
+
+	for _, id := range graph.StubGroups {
+		r.mergeStub(models.LabelGroup, id)
+	}
+
+	r.mergeRelationships(graph.Relationships)
+	return nil
+}
+
+// mergeStub Создает узел только с VK id, если его нет.
+func (r *UserMemoryRepo) mergeStub(label string, id uint64) {
+	if label == models.LabelGroup {
+		if n := r.mergeNode(r.groups, id, label); n.group.ID == 0 {
+			n.group.ID = id
+		}
+		return
+	}
+
+	if n := r.mergeNode(r.users, id, label); n.user.ID == 0 {
+		n.user.ID = id
+	}
+}
+
+func validateRelationships(relationships []models.Relationship) error {
+	for _, rel := range relationships {
+		switch {
+		case rel.Type == models.RelationshipFollow && rel.TargetLabel == models.LabelUser,
+			rel.Type == models.RelationshipSubscribe && rel.TargetLabel == models.LabelUser,
+			rel.Type == models.RelationshipSubscribe && rel.TargetLabel == models.LabelGroup:
+		default:
+			return fmt.Errorf("unsupported relationship (:User)-[:%s]->(:%s)", rel.Type, rel.TargetLabel)
+		}
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepo) mergeRelationships(relationships []models.Relationship) {
+	for _, rel := range relationships {
+		targets := r.users
+		if rel.TargetLabel == models.LabelGroup {
+			targets = r.groups
+		}
+
+		r.mergeEdge(rel.Type, r.users, rel.SourceID, targets, rel.TargetID)
+	}
+}
+
+// mergeEdge Как MATCH ... MERGE: связь создается, только если оба узла уже есть.
+func (r *UserMemoryRepo) mergeEdge(relType string, fromIndex map[uint64]int64, fromID uint64, toIndex map[uint64]int64, toID uint64) {
+	from, ok := fromIndex[fromID]
+	if !ok {
+		return
+	}
+
+	to, ok := toIndex[toID]
+	if !ok {
+		return
+	}
+
+	e := edge{relType: relType, from: from, to: to}
+	if _, ok := r.edgeSet[e]; ok {
+		return
+	}
+
+	r.edgeSet[e] = struct{}{}
+	r.edges = append(r.edges, e)
+}
+
+// elementIDPrefix Префикс elementId узлов в памяти, за ним следует внутренний номер узла.
+const elementIDPrefix = "memory:"
+
+func (n *node) elementID() string {
+	return elementIDPrefix + strconv.FormatInt(n.id, 10)
+}
+
+func (n *node) vkID() uint64 {
+	if n.label == models.LabelGroup {
+		return n.group.ID
+	}
+
+	return n.user.ID
+}
+
+// lookup Узел по метке и VK id или по elementId.
+func (r *UserMemoryRepo) lookup(ref models.NodeRef) (*node, error) {
+	if ref.ElementID != "" {
+		idStr, ok := strings.CutPrefix(ref.ElementID, elementIDPrefix)
+		if !ok {
+			return nil, nil
+		}
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, nil
+		}
+
+		return r.nodes[id], nil
+	}
+
+	var index map[uint64]int64
+	switch ref.Label {
+	case models.LabelUser:
+		index = r.users
+	case models.LabelGroup:
+		index = r.groups
+	default:
+		return nil, fmt.Errorf("unsupported node label %q", ref.Label)
+	}
+
+	id, ok := index[ref.ID]
+	if !ok {
+		return nil, nil
+	}
+
+	return r.nodes[id], nil
+}
+
+func (r *UserMemoryRepo) DeleteNode(_ context.Context, ref models.NodeRef) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, err := r.lookup(ref)
+	if err != nil || n == nil {
+		return err
+	}
+
+	delete(r.nodes, n.id)
+	switch n.label {
+	case models.LabelUser:
+		delete(r.users, n.user.ID)
+	case models.LabelGroup:
+		delete(r.groups, n.group.ID)
+	}
+
+	r.edges = slices.DeleteFunc(r.edges, func(e edge) bool {
+		if e.from != n.id && e.to != n.id {
+			return false
+		}
+
+		delete(r.edgeSet, e)
+		return true
+	})
+
+	return nil
+}
+
+func (r *UserMemoryRepo) GetUsersCount(context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.users), nil
+}
+
+func (r *UserMemoryRepo) GetGroupsCount(context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.groups), nil
+}
+
+// GetTopUsersByFollowersCount Пользователи с наибольшим числом подписчиков, при равенстве по VK id.
+func (r *UserMemoryRepo) GetTopUsersByFollowersCount(_ context.Context, limit int) ([]models.TopUser, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []models.TopUser
+	for _, t := range r.topTargets(models.RelationshipFollow, models.LabelUser, limit) {
+		users = append(users, models.TopUser{User: t.node.user, FollowersCount: t.count})
+	}
+
+	return users, nil
+}
+
+// GetTopGroupsBySubscribersCount Группы с наибольшим числом подписчиков-пользователей, при равенстве по VK id.
+func (r *UserMemoryRepo) GetTopGroupsBySubscribersCount(_ context.Context, limit int) ([]models.TopGroup, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups []models.TopGroup
+	for _, t := range r.topTargets(models.RelationshipSubscribe, models.LabelGroup, limit) {
+		groups = append(groups, models.TopGroup{Group: t.node.group, SubscribersCount: t.count})
+	}
+
+	return groups, nil
+}
+
+// target Узел и число входящих в него связей.
+type target struct {
+	node  *node
+	count int
+}
+
+// topTargets Узлы с меткой label, упорядоченные по числу входящих связей relType от пользователей.
+// Узлы без входящих связей не попадают в выборку. При равенстве порядок по VK id.
+func (r *UserMemoryRepo) topTargets(relType, label string, limit int) []target {
+	counts := make(map[int64]int)
+	for _, e := range r.edges {
+		if e.relType == relType && r.nodes[e.to].label == label && r.nodes[e.from].label == models.LabelUser {
+			counts[e.to]++
+		}
+	}
+
+	targets := make([]target, 0, len(counts))
+	for id, count := range counts {
+		targets = append(targets, target{node: r.nodes[id], count: count})
+	}
+
+	slices.SortFunc(targets, func(a, b target) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.node.vkID(), b.node.vkID())
+	})
+
+	return targets[:min(limit, len(targets))]
+}
+
+// GetUsersWithDifferentGroups Пользователи с подписками на группы, ни на одну из которых не подписан
+// другой пользователь, по VK id. Повторяет запрос UserNeo4jRepo.
+func (r *UserMemoryRepo) GetUsersWithDifferentGroups(_ context.Context, limit int) ([]models.User, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make(map[int64][]int64)
+	subscribers := make(map[int64]int)
+	for _, e := range r.edges {
+		if e.relType == models.RelationshipSubscribe && r.nodes[e.to].label == models.LabelGroup &&
+			r.nodes[e.from].label == models.LabelUser {
+			groups[e.from] = append(groups[e.from], e.to)
+			subscribers[e.to]++
+		}
+	}
+
+	var users []models.User
+	for id, userGroups := range groups {
+		exclusive := !slices.ContainsFunc(userGroups, func(group int64) bool {
+			return subscribers[group] > 1
+		})
+		if exclusive {
+			users = append(users, r.nodes[id].user)
+		}
+	}
+
+	return sortUsers(users)[:min(limit, len(users))], nil
+}
+
+func (r *UserMemoryRepo) sortedNodes() []*node {
+	nodes := make([]*node, 0, len(r.nodes))
+	for _, n := range r.nodes {
+		nodes = append(nodes, n)
+	}
+
+	slices.SortFunc(nodes, func(a, b *node) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	return nodes
+}
+
+// GetNodeWithRelationships Возвращает models.User или models.GroupWithSubscribers, nil - если узла нет.
+func (r *UserMemoryRepo) GetNodeWithRelationships(_ context.Context, ref models.NodeRef) (interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, err := r.lookup(ref)
+	if err != nil || n == nil {
+		return nil, err
+	}
+
+	// Сначала исходящие связи, затем входящие.
+	var related []edge
+	for _, e := range r.edges {
+		if e.from == n.id {
+			related = append(related, e)
+		}
+	}
+	for _, e := range r.edges {
+		if e.to == n.id {
+			related = append(related, e)
+		}
+	}
+
+	switch n.label {
+	case models.LabelUser:
+		user := n.user
+		for _, e := range related {
+			m := r.nodes[e.from]
+			if e.from == n.id {
+				m = r.nodes[e.to]
+			}
+
+			processUserRelation(&user, m, e.relType)
+		}
+
+		return user, nil
+	case models.LabelGroup:
+		group := models.GroupWithSubscribers{Group: n.group}
+		for _, e := range related {
+			m := r.nodes[e.from]
+			if e.from == n.id {
+				m = r.nodes[e.to]
+			}
+
+			group.Subscribers = append(group.Subscribers, m.user)
+		}
+
+		return group, nil
+	}
+
+	return nil, fmt.Errorf("can not find labels for node %d", n.id)
+}
+
+func processUserRelation(user *models.User, m *node, relType string) {
+	switch relType {
+	case models.RelationshipFollow:
+		user.Followers = append(user.Followers, m.user)
+	case models.RelationshipSubscribe:
+		switch m.label {
+		case models.LabelGroup:
+			user.Subscriptions.Groups = append(user.Subscriptions.Groups, m.group)
+		case models.LabelUser:
+			user.Subscriptions.Users = append(user.Subscriptions.Users, m.user)
+		}
+	}
+}
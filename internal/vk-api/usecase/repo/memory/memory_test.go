@@ -0,0 +1,562 @@
+package memory
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+// newTestRepo Граф: 2 -> 1, 3 -> 1, 3 -> 2 (Follow), 1 и 2 подписаны на группу 100, 3 на пользователя 1.
+func newTestRepo(t *testing.T) *UserMemoryRepo {
+	t.Helper()
+	ctx := context.Background()
+
+	r := NewUserMemoryRepo()
+	for _, id := range []uint64{1, 2, 3} {
+		require.NoError(t, r.CreateUser(ctx, models.User{ID: id, FirstName: "User"}))
+	}
+	require.NoError(t, r.CreateGroup(ctx, models.Group{ID: 100, Name: "Group"}))
+
+	u := func(id uint64) models.User { return models.User{ID: id} }
+	require.NoError(t, r.CreateFollowRelationship(ctx, u(2), u(1)))
+	require.NoError(t, r.CreateFollowRelationship(ctx, u(3), u(1)))
+	require.NoError(t, r.CreateFollowRelationship(ctx, u(3), u(2)))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, u(1), models.Group{ID: 100}))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, u(2), models.Group{ID: 100}))
+	require.NoError(t, r.CreateSubscribeUserUserRelationship(ctx, u(3), u(1)))
+
+	return r
+}
+
+func TestMergeIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 1, FirstName: "Renamed"}))
+	require.NoError(t, r.CreateFollowRelationship(ctx, models.User{ID: 2}, models.User{ID: 1}))
+	// Связь с несуществующим узлом не создается.
+	require.NoError(t, r.CreateFollowRelationship(ctx, models.User{ID: 42}, models.User{ID: 1}))
+
+	users, err := r.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, users)
+
+	groups, err := r.GetGroupsCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, groups)
+
+	node, err := r.GetNodeWithRelationships(ctx, userRef(1))
+	require.NoError(t, err)
+
+	user := node.(models.User)
+	require.Equal(t, "Renamed", user.FirstName)
+	require.Len(t, user.Followers, 2)
+}
+
+func TestGetNodeWithRelationships(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	node, err := r.GetNodeWithRelationships(ctx, userRef(1))
+	require.NoError(t, err)
+
+	user := node.(models.User)
+	require.Equal(t, uint64(1), user.ID)
+	require.Equal(t, []uint64{2, 3}, userIDs(user.Followers))
+	require.Equal(t, []models.Group{{ID: 100, Name: "Group"}}, user.Subscriptions.Groups)
+	require.Equal(t, []uint64{3}, userIDs(user.Subscriptions.Users))
+
+	node, err = r.GetNodeWithRelationships(ctx, groupRef(100))
+	require.NoError(t, err)
+
+	group := node.(models.GroupWithSubscribers)
+	require.Equal(t, uint64(100), group.ID)
+	require.Equal(t, []uint64{1, 2}, userIDs(group.Subscribers))
+
+	node, err = r.GetNodeWithRelationships(ctx, models.NodeRef{ElementID: "memory:3"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), node.(models.GroupWithSubscribers).ID)
+
+	for _, ref := range []models.NodeRef{
+		userRef(42),
+		groupRef(1),
+		{ElementID: "memory:42"},
+		{ElementID: "4:abc:0"},
+	} {
+		node, err = r.GetNodeWithRelationships(ctx, ref)
+		require.NoError(t, err)
+		require.Nil(t, node)
+	}
+
+	_, err = r.GetNodeWithRelationships(ctx, models.NodeRef{Label: "Post", ID: 1})
+	require.Error(t, err)
+}
+
+func TestUsersAndGroups(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	users, err := r.GetUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3}, userIDs(users))
+
+	groups, err := r.GetGroups(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.Group{{ID: 100, Name: "Group"}}, groups)
+
+	followers, err := r.GetFollowers(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, userIDs(followers))
+
+	subscriptions, err := r.GetSubscriptions(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, userIDs(subscriptions.Users))
+	require.Empty(t, subscriptions.Groups)
+
+	subscribers, err := r.GetSubscribers(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, userIDs(subscribers))
+
+	ok, err := r.UpdateUser(ctx, models.User{ID: 2, FirstName: "Updated"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	user, err := r.FindUser(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, &models.User{ID: 2, FirstName: "Updated"}, user)
+
+	ok, err = r.UpdateGroup(ctx, models.Group{ID: 100, Name: "Renamed"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	group, err := r.FindGroup(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, &models.Group{ID: 100, Name: "Renamed"}, group)
+
+	// Обновление не создает узлы и не меняет связи.
+	ok, err = r.UpdateUser(ctx, models.User{ID: 42})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	user, err = r.FindUser(ctx, 42)
+	require.NoError(t, err)
+	require.Nil(t, user)
+
+	followers, err = r.GetFollowers(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, userIDs(followers))
+}
+
+func TestRelationships(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	rels, err := r.GetRelationships(ctx, models.RelationshipFilter{SourceID: 3})
+	require.NoError(t, err)
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 3, TargetID: 1, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipFollow, SourceID: 3, TargetID: 2, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipSubscribe, SourceID: 3, TargetID: 1, TargetLabel: models.LabelUser},
+	}, rels)
+
+	rels, err = r.GetRelationships(ctx, models.RelationshipFilter{Type: models.RelationshipSubscribe, TargetLabel: models.LabelGroup})
+	require.NoError(t, err)
+	require.Len(t, rels, 2)
+
+	follow := models.Relationship{Type: models.RelationshipFollow, SourceID: 3, TargetID: 1, TargetLabel: models.LabelUser}
+	ok, err := r.DeleteRelationship(ctx, follow)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = r.DeleteRelationship(ctx, follow)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = r.DeleteRelationship(ctx, models.Relationship{Type: models.RelationshipFollow, SourceID: 1, TargetID: 100, TargetLabel: models.LabelGroup})
+	require.Error(t, err)
+
+	followers, err := r.GetFollowers(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, userIDs(followers))
+
+	// Удаленную связь можно создать заново.
+	require.NoError(t, r.CreateFollowRelationship(ctx, models.User{ID: 3}, models.User{ID: 1}))
+	followers, err = r.GetFollowers(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, userIDs(followers))
+}
+
+func TestGetNodes(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 4, FirstName: "Anna", Sex: 1, City: models.City{Title: "Tyumen"}}))
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 100, FirstName: "Boris", Sex: 2, City: models.City{Title: "Tyumen"}}))
+
+	// Пользователь и группа с VK id 100 различаются меткой.
+	var ids []string
+	query := models.NodeQuery{Sort: models.NodeSortID, Limit: 2}
+	for {
+		nodes, next, err := r.GetNodes(ctx, query)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(nodes), 2)
+
+		for _, n := range nodes {
+			ids = append(ids, n.Label+":"+strconv.FormatUint(n.ID, 10))
+		}
+
+		if next == nil {
+			break
+		}
+		query.After = next
+	}
+	require.Equal(t, []string{"User:1", "User:2", "User:3", "User:4", "Group:100", "User:100"}, ids)
+
+	nodes, next, err := r.GetNodes(ctx, models.NodeQuery{Sort: models.NodeSortID, Desc: true, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []models.Node{
+		{ID: 100, Label: models.LabelUser, ElementID: "memory:5"},
+		{ID: 100, Label: models.LabelGroup, ElementID: "memory:3"},
+	}, nodes)
+
+	nodes, _, err = r.GetNodes(ctx, models.NodeQuery{Sort: models.NodeSortID, Desc: true, Limit: 2, After: next})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), nodes[0].ID)
+
+	sex := byte(2)
+	nodes, next, err = r.GetNodes(ctx, models.NodeQuery{City: "Tyumen", Sex: &sex, Sort: models.NodeSortID, Limit: 10, WithProperties: true})
+	require.NoError(t, err)
+	require.Nil(t, next)
+	require.Equal(t, []models.Node{{
+		ID:        100,
+		Label:     models.LabelUser,
+		ElementID: "memory:5",
+		Properties: map[string]interface{}{
+			"id": int64(100), "screen_name": "", "name": "Boris ", "sex": int64(2), "city": "Tyumen",
+		},
+	}}, nodes)
+
+	nodes, _, err = r.GetNodes(ctx, models.NodeQuery{NamePrefix: "GR", Sort: models.NodeSortName, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []models.Node{{ID: 100, Label: models.LabelGroup, ElementID: "memory:3"}}, nodes)
+
+	nodes, next, err = r.GetNodes(ctx, models.NodeQuery{Label: models.LabelUser, Sort: models.NodeSortName, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 100}, nodeIDs(nodes))
+	require.Equal(t, &models.NodeCursor{Sort: models.NodeSortName, Name: "Boris ", Label: models.LabelUser, ID: 100}, next)
+
+	nodes, next, err = r.GetNodes(ctx, models.NodeQuery{Label: models.LabelUser, Sort: models.NodeSortName, Limit: 2, After: next})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, nodeIDs(nodes))
+	require.NotNil(t, next)
+
+	_, _, err = r.GetNodes(ctx, models.NodeQuery{Label: "Post", Limit: 10})
+	require.Error(t, err)
+}
+
+func nodeIDs(nodes []models.Node) []uint64 {
+	var ids []uint64
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestTopQueries(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	topUsers, err := r.GetTopUsersByFollowersCount(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, topUsers, 2)
+	require.Equal(t, uint64(1), topUsers[0].ID)
+	require.Equal(t, 2, topUsers[0].FollowersCount)
+	require.Equal(t, uint64(2), topUsers[1].ID)
+	require.Equal(t, 1, topUsers[1].FollowersCount)
+
+	topUsers, err = r.GetTopUsersByFollowersCount(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, topUsers, 1)
+	require.Equal(t, uint64(1), topUsers[0].ID)
+
+	groups, err := r.GetTopGroupsBySubscribersCount(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, uint64(100), groups[0].ID)
+	require.Equal(t, 2, groups[0].SubscribersCount)
+
+	// 1 и 2 делят группу 100.
+	users, err := r.GetUsersWithDifferentGroups(ctx, 5)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	// У 4 группа 200 только своя, подписка на пользователя не в счет. У 5 своя группа 300,
+	// но группу 100 он делит с 1 и 2.
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 4}))
+	require.NoError(t, r.CreateGroup(ctx, models.Group{ID: 200}))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, models.User{ID: 4}, models.Group{ID: 200}))
+	require.NoError(t, r.CreateSubscribeUserUserRelationship(ctx, models.User{ID: 4}, models.User{ID: 1}))
+
+	groups, err = r.GetTopGroupsBySubscribersCount(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Equal(t, uint64(200), groups[1].ID)
+	require.Equal(t, 1, groups[1].SubscribersCount)
+
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 5}))
+	require.NoError(t, r.CreateGroup(ctx, models.Group{ID: 300}))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, models.User{ID: 5}, models.Group{ID: 300}))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, models.User{ID: 5}, models.Group{ID: 100}))
+
+	users, err = r.GetUsersWithDifferentGroups(ctx, 5)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4}, userIDs(users))
+
+	users, err = r.GetUsersWithDifferentGroups(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestDeleteNode(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	require.NoError(t, r.DeleteNode(ctx, groupRef(100)))
+	require.NoError(t, r.DeleteNode(ctx, models.NodeRef{ElementID: "memory:3"}))
+
+	nodes, next, err := r.GetNodes(ctx, models.NodeQuery{Sort: models.NodeSortID, Limit: 10})
+	require.NoError(t, err)
+	require.Nil(t, next)
+	require.Equal(t, []models.Node{
+		{ID: 1, Label: models.LabelUser, ElementID: "memory:0"},
+		{ID: 2, Label: models.LabelUser, ElementID: "memory:1"},
+		{ID: 3, Label: models.LabelUser, ElementID: "memory:2"},
+	}, nodes)
+
+	node, err := r.GetNodeWithRelationships(ctx, userRef(1))
+	require.NoError(t, err)
+	require.Nil(t, node.(models.User).Subscriptions.Groups)
+
+	groups, err := r.GetTopGroupsBySubscribersCount(ctx, 5)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+}
+
+func userRef(id uint64) models.NodeRef {
+	return models.NodeRef{Label: models.LabelUser, ID: id}
+}
+
+func groupRef(id uint64) models.NodeRef {
+	return models.NodeRef{Label: models.LabelGroup, ID: id}
+}
+
+func userIDs(users []models.User) []uint64 {
+	var ids []uint64
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestSaveGraphIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	r := NewUserMemoryRepo()
+
+	err := r.SaveGraph(ctx, models.Graph{
+		Users: []models.User{{ID: 1}, {ID: 2}},
+		Relationships: []models.Relationship{
+			{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+			{Type: "Likes", SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+		},
+	})
+	require.Error(t, err)
+
+	users, err := r.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Zero(t, users)
+
+	require.NoError(t, r.SaveGraph(ctx, models.Graph{
+		Users:  []models.User{{ID: 1}, {ID: 2}},
+		Groups: []models.Group{{ID: 100}},
+		Relationships: []models.Relationship{
+			{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+			{Type: models.RelationshipSubscribe, SourceID: 2, TargetID: 100, TargetLabel: models.LabelGroup},
+		},
+	}))
+
+	node, err := r.GetNodeWithRelationships(ctx, userRef(2))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, userIDs(node.(models.User).Followers))
+	require.Len(t, node.(models.User).Subscriptions.Groups, 1)
+}
+
+func TestSaveGraphStubs(t *testing.T) {
+	ctx := context.Background()
+	r := NewUserMemoryRepo()
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 1, FirstName: "Kept"}))
+
+	require.NoError(t, r.SaveGraph(ctx, models.Graph{
+		StubUsers:  []uint64{1, 2},
+		StubGroups: []uint64{100},
+		Relationships: []models.Relationship{
+			{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+			{Type: models.RelationshipSubscribe, SourceID: 1, TargetID: 100, TargetLabel: models.LabelGroup},
+		},
+	}))
+
+	user, err := r.FindUser(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Kept", user.FirstName)
+
+	node, err := r.GetNodeWithRelationships(ctx, userRef(1))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, userIDs(node.(models.User).Followers))
+	require.Equal(t, []models.Group{{ID: 100}}, node.(models.User).Subscriptions.Groups)
+}
+
+func TestCreateStubNodes(t *testing.T) {
+	ctx := context.Background()
+	r := NewUserMemoryRepo()
+	require.NoError(t, r.CreateGroup(ctx, models.Group{ID: 100, Name: "Kept"}))
+
+	require.NoError(t, r.CreateStubNodes(ctx, models.LabelUser, []uint64{1, 2}))
+	require.NoError(t, r.CreateStubNodes(ctx, models.LabelGroup, []uint64{100, 200}))
+	require.EqualError(t, r.CreateStubNodes(ctx, "City", []uint64{1}), `write stub nodes: unsupported node label "City"`)
+
+	users, err := r.GetUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, userIDs(users))
+
+	groups, err := r.GetGroups(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.Group{{ID: 100, Name: "Kept"}, {ID: 200}}, groups)
+}
+
+func TestFindShortestPaths(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	// 4 связан с остальными только через группу 100.
+	require.NoError(t, r.CreateUser(ctx, models.User{ID: 4}))
+	require.NoError(t, r.CreateGroup(ctx, models.Group{ID: 200}))
+	require.NoError(t, r.CreateSubscribeUserGroupRelationship(ctx, models.User{ID: 4}, models.Group{ID: 100}))
+
+	follow := []string{models.RelationshipFollow}
+	all := []string{models.RelationshipFollow, models.RelationshipSubscribe}
+	query := func(source, target uint64, types []string, direction string) models.PathQuery {
+		return models.PathQuery{SourceID: source, TargetID: target, Types: types, Direction: direction, MaxHops: 6, Limit: 10}
+	}
+
+	paths, err := r.FindShortestPaths(ctx, query(2, 3, follow, models.DirectionOut))
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	paths, err = r.FindShortestPaths(ctx, query(2, 3, follow, models.DirectionIn))
+	require.NoError(t, err)
+	require.Equal(t, []models.Path{{
+		Length: 1,
+		Nodes: []models.Node{
+			{ID: 2, Label: models.LabelUser, ElementID: "memory:1", Properties: map[string]interface{}{
+				"id": int64(2), "screen_name": "", "name": "User ", "sex": int64(0), "city": "",
+			}},
+			{ID: 3, Label: models.LabelUser, ElementID: "memory:2", Properties: map[string]interface{}{
+				"id": int64(3), "screen_name": "", "name": "User ", "sex": int64(0), "city": "",
+			}},
+		},
+		Relationships: []models.Relationship{
+			{Type: models.RelationshipFollow, SourceID: 3, TargetID: 2, TargetLabel: models.LabelUser},
+		},
+	}}, paths)
+
+	// Между 1 и 3 две связи: Follow и Subscribe.
+	q := query(1, 3, all, models.DirectionBoth)
+	paths, err = r.FindShortestPaths(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+
+	q.All = true
+	paths, err = r.FindShortestPaths(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	require.Equal(t, models.RelationshipFollow, paths[0].Relationships[0].Type)
+	require.Equal(t, models.RelationshipSubscribe, paths[1].Relationships[0].Type)
+
+	q = query(4, 1, all, models.DirectionBoth)
+	paths, err = r.FindShortestPaths(ctx, q)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	q.ViaGroups = true
+	paths, err = r.FindShortestPaths(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Equal(t, 2, paths[0].Length)
+	require.Equal(t, []uint64{4, 100, 1}, nodeIDs(paths[0].Nodes))
+	require.Equal(t, models.LabelGroup, paths[0].Nodes[1].Label)
+
+	q.MaxHops = 1
+	paths, err = r.FindShortestPaths(ctx, q)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	paths, err = r.FindShortestPaths(ctx, query(1, 42, all, models.DirectionBoth))
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	_, err = r.FindShortestPaths(ctx, query(1, 2, []string{"Like"}, models.DirectionBoth))
+	require.Error(t, err)
+}
+
+func TestGetEgoNetwork(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	all := []string{models.RelationshipFollow, models.RelationshipSubscribe}
+
+	ego, err := r.GetEgoNetwork(ctx, models.EgoQuery{Center: userRef(1), Hops: 1, Types: all, Direction: models.DirectionBoth, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 100, 2, 3}, nodeIDs(ego.Nodes))
+	require.Equal(t, "memory:0", ego.Nodes[0].ElementID)
+	require.Equal(t, "Group", ego.Nodes[1].Properties["name"])
+	require.Len(t, ego.Relationships, 6)
+	require.False(t, ego.Truncated)
+
+	ego, err = r.GetEgoNetwork(ctx, models.EgoQuery{
+		Center: userRef(3), Hops: 2, Types: []string{models.RelationshipFollow}, Direction: models.DirectionOut, Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 1, 2}, nodeIDs(ego.Nodes))
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipFollow, SourceID: 3, TargetID: 1, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipFollow, SourceID: 3, TargetID: 2, TargetLabel: models.LabelUser},
+	}, ego.Relationships)
+
+	ego, err = r.GetEgoNetwork(ctx, models.EgoQuery{Center: groupRef(100), Hops: 1, Types: all, Direction: models.DirectionBoth, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{100, 1, 2}, nodeIDs(ego.Nodes))
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipSubscribe, SourceID: 1, TargetID: 100, TargetLabel: models.LabelGroup},
+		{Type: models.RelationshipSubscribe, SourceID: 2, TargetID: 100, TargetLabel: models.LabelGroup},
+	}, ego.Relationships)
+
+	// Узлы берутся по удаленности, затем по метке и VK id.
+	ego, err = r.GetEgoNetwork(ctx, models.EgoQuery{Center: userRef(1), Hops: 2, Types: all, Direction: models.DirectionBoth, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 100}, nodeIDs(ego.Nodes))
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipSubscribe, SourceID: 1, TargetID: 100, TargetLabel: models.LabelGroup},
+	}, ego.Relationships)
+	require.True(t, ego.Truncated)
+
+	ego, err = r.GetEgoNetwork(ctx, models.EgoQuery{Center: userRef(1), Hops: 1, Types: all, Direction: models.DirectionOut, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 100}, nodeIDs(ego.Nodes))
+	require.False(t, ego.Truncated)
+
+	ego, err = r.GetEgoNetwork(ctx, models.EgoQuery{Center: userRef(42), Hops: 1, Types: all, Direction: models.DirectionBoth, Limit: 10})
+	require.NoError(t, err)
+	require.Nil(t, ego)
+}
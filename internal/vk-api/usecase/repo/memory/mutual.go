@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+// GetMutualFollowers Пользователи, подписанные на каждого из userIDs.
+func (r *UserMemoryRepo) GetMutualFollowers(_ context.Context, userIDs []uint64) ([]models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes, union := r.overlap(userIDs, models.RelationshipFollow, models.LabelUser, true)
+
+	users := make([]models.User, 0, len(nodes))
+	for _, n := range nodes {
+		users = append(users, n.user)
+	}
+
+	return sortUsers(users), union, nil
+}
+
+// GetMutualSubscriptions Пользователи, на которых подписан каждый из userIDs.
+func (r *UserMemoryRepo) GetMutualSubscriptions(_ context.Context, userIDs []uint64) ([]models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes, union := r.overlap(userIDs, models.RelationshipSubscribe, models.LabelUser, false)
+
+	users := make([]models.User, 0, len(nodes))
+	for _, n := range nodes {
+		users = append(users, n.user)
+	}
+
+	return sortUsers(users), union, nil
+}
+
+// GetCommonGroups Группы, на которые подписан каждый из userIDs.
+func (r *UserMemoryRepo) GetCommonGroups(_ context.Context, userIDs []uint64) ([]models.Group, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes, union := r.overlap(userIDs, models.RelationshipSubscribe, models.LabelGroup, false)
+
+	groups := make([]models.Group, 0, len(nodes))
+	for _, n := range nodes {
+		groups = append(groups, n.group)
+	}
+
+	return sortGroups(groups), union, nil
+}
+
+// overlap Узлы с меткой label, связанные связью relType с каждым из userIDs, и число узлов, связанных
+// хотя бы с одним. incoming - связь ведет от узла к пользователю, иначе от пользователя к узлу.
+func (r *UserMemoryRepo) overlap(userIDs []uint64, relType, label string, incoming bool) ([]*node, int) {
+	members := make(map[int64]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		if n, ok := r.users[id]; ok {
+			members[n] = struct{}{}
+		}
+	}
+
+	// Связи не повторяются, поэтому число связей узла с набором равно числу связанных с ним пользователей.
+	linked := make(map[int64]int)
+	for _, e := range r.edges {
+		member, other := e.from, e.to
+		if incoming {
+			member, other = e.to, e.from
+		}
+
+		if _, ok := members[member]; !ok || e.relType != relType || r.nodes[other].label != label {
+			continue
+		}
+
+		linked[other]++
+	}
+
+	var common []*node
+	for id, count := range linked {
+		if count == len(userIDs) {
+			common = append(common, r.nodes[id])
+		}
+	}
+
+	return common, len(linked)
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+	"strings"
+)
+
+// name Свойство name узла, как его хранит UserNeo4jRepo.
+func (n *node) name() string {
+	if n.label == models.LabelGroup {
+		return n.group.Name
+	}
+
+	return n.user.FirstName + " " + n.user.LastName
+}
+
+// properties Свойства узла с теми же ключами и типами, что возвращает Neo4j.
+func (n *node) properties() map[string]interface{} {
+	if n.label == models.LabelGroup {
+		return map[string]interface{}{
+			"id":          int64(n.group.ID),
+			"name":        n.group.Name,
+			"screen_name": n.group.ScreenName,
+		}
+	}
+
+	return map[string]interface{}{
+		"id":          int64(n.user.ID),
+		"screen_name": n.user.ScreenName,
+		"name":        n.name(),
+		"sex":         int64(n.user.Sex),
+		"city":        n.user.City.Title,
+	}
+}
+
+func (n *node) matches(q models.NodeQuery) bool {
+	if q.Label != "" && q.Label != n.label {
+		return false
+	}
+
+	if (q.City != "" || q.Sex != nil) && n.label != models.LabelUser {
+		return false
+	}
+
+	if q.City != "" && q.City != n.user.City.Title {
+		return false
+	}
+
+	if q.Sex != nil && *q.Sex != n.user.Sex {
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToLower(n.name()), strings.ToLower(q.NamePrefix))
+}
+
+// compareNodes Порядок узлов как в UserNeo4jRepo: по id и метке или по имени, метке и id.
+func compareNodes(sort string, a, b models.NodeCursor) int {
+	if sort == models.NodeSortName {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Label, b.Label), cmp.Compare(a.ID, b.ID))
+	}
+
+	return cmp.Or(cmp.Compare(a.ID, b.ID), cmp.Compare(a.Label, b.Label))
+}
+
+// GetNodes Страница узлов по запросу. Курсор указывает на последний узел страницы, nil - если страница последняя.
+func (r *UserMemoryRepo) GetNodes(_ context.Context, q models.NodeQuery) ([]models.Node, *models.NodeCursor, error) {
+	switch q.Label {
+	case "", models.LabelUser, models.LabelGroup:
+	default:
+		return nil, nil, fmt.Errorf("unsupported node label %q", q.Label)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	position := func(n *node) models.NodeCursor {
+		c := models.NodeCursor{Sort: q.Sort, Desc: q.Desc, Label: n.label, ID: n.vkID()}
+		if q.Sort == models.NodeSortName {
+			c.Name = n.name()
+		}
+		return c
+	}
+
+	direction := 1
+	if q.Desc {
+		direction = -1
+	}
+
+	var selected []*node
+	for _, n := range r.nodes {
+		if !n.matches(q) {
+			continue
+		}
+
+		if q.After != nil && direction*compareNodes(q.Sort, position(n), *q.After) <= 0 {
+			continue
+		}
+
+		selected = append(selected, n)
+	}
+
+	slices.SortFunc(selected, func(a, b *node) int {
+		return direction * compareNodes(q.Sort, position(a), position(b))
+	})
+
+	nodes := make([]models.Node, 0, min(q.Limit, len(selected)))
+	for _, n := range selected[:min(q.Limit, len(selected))] {
+		node := models.Node{ID: n.vkID(), Label: n.label, ElementID: n.elementID()}
+		if q.WithProperties {
+			node.Properties = n.properties()
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if len(selected) <= q.Limit {
+		return nodes, nil, nil
+	}
+
+	cursor := position(selected[q.Limit-1])
+	return nodes, &cursor, nil
+}
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// step Переход между узлами по связи e при поиске пути.
+type step struct {
+	to int64
+	e  edge
+}
+
+// FindShortestPaths Кратчайшие пути между пользователями поиском в ширину.
+// Пути перечисляются в порядке создания связей, а не в порядке Neo4j.
+func (r *UserMemoryRepo) FindShortestPaths(_ context.Context, q models.PathQuery) ([]models.Path, error) {
+	if err := validateTraversal(q.Types, q.Direction); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	paths := make([]models.Path, 0)
+
+	source, ok := r.users[q.SourceID]
+	if !ok {
+		return paths, nil
+	}
+
+	target, ok := r.users[q.TargetID]
+	if !ok {
+		return paths, nil
+	}
+
+	adjacent := r.steps(q.Types, q.Direction, !q.ViaGroups)
+
+	// preds Для каждого достигнутого узла - переходы, которыми он достигается кратчайшим путем.
+	dist := map[int64]int{source: 0}
+	preds := make(map[int64][]step)
+	frontier := []int64{source}
+	for hops := 1; hops <= q.MaxHops && len(frontier) > 0; hops++ {
+		if _, ok := dist[target]; ok {
+			break
+		}
+
+		var next []int64
+		for _, id := range frontier {
+			for _, s := range adjacent[id] {
+				if d, ok := dist[s.to]; !ok {
+					dist[s.to] = hops
+					next = append(next, s.to)
+				} else if d != hops {
+					continue
+				}
+
+				preds[s.to] = append(preds[s.to], step{to: id, e: s.e})
+			}
+		}
+
+		frontier = next
+	}
+
+	if _, ok := dist[target]; !ok {
+		return paths, nil
+	}
+
+	limit := q.Limit
+	if !q.All {
+		limit = 1
+	}
+
+	// Пути собираются от target к source и разворачиваются.
+	var walk func(id int64, nodes []int64, edges []edge)
+	walk = func(id int64, nodes []int64, edges []edge) {
+		if len(paths) == limit {
+			return
+		}
+
+		if id == source {
+			slices.Reverse(nodes)
+			slices.Reverse(edges)
+			paths = append(paths, r.path(nodes, edges))
+			return
+		}
+
+		for _, s := range preds[id] {
+			walk(s.to, append(slices.Clip(nodes), s.to), append(slices.Clip(edges), s.e))
+		}
+	}
+	walk(target, []int64{target}, nil)
+
+	return paths, nil
+}
+
+// steps Переходы по связям types в направлении direction. usersOnly - без связей с группами.
+func (r *UserMemoryRepo) steps(types []string, direction string, usersOnly bool) map[int64][]step {
+	adjacent := make(map[int64][]step)
+	for _, e := range r.edges {
+		if !slices.Contains(types, e.relType) {
+			continue
+		}
+
+		if usersOnly && (r.nodes[e.from].label != models.LabelUser || r.nodes[e.to].label != models.LabelUser) {
+			continue
+		}
+
+		if direction != models.DirectionIn {
+			adjacent[e.from] = append(adjacent[e.from], step{to: e.to, e: e})
+		}
+		if direction != models.DirectionOut {
+			adjacent[e.to] = append(adjacent[e.to], step{to: e.from, e: e})
+		}
+	}
+
+	return adjacent
+}
+
+func validateTraversal(types []string, direction string) error {
+	if err := validateTypes(types); err != nil {
+		return err
+	}
+
+	switch direction {
+	case models.DirectionOut, models.DirectionIn, models.DirectionBoth:
+	default:
+		return fmt.Errorf("unsupported direction %q", direction)
+	}
+
+	return nil
+}
+
+func validateTypes(types []string) error {
+	for _, relType := range types {
+		if relType != models.RelationshipFollow && relType != models.RelationshipSubscribe {
+			return fmt.Errorf("unsupported relationship type %q", relType)
+		}
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepo) path(nodes []int64, edges []edge) models.Path {
+	path := models.Path{
+		Length:        len(edges),
+		Nodes:         make([]models.Node, 0, len(nodes)),
+		Relationships: make([]models.Relationship, 0, len(edges)),
+	}
+
+	for _, id := range nodes {
+		path.Nodes = append(path.Nodes, r.nodes[id].node())
+	}
+
+	for _, e := range edges {
+		path.Relationships = append(path.Relationships, r.relationship(e))
+	}
+
+	return path
+}
+
+// node Узел со всеми свойствами.
+func (n *node) node() models.Node {
+	return models.Node{ID: n.vkID(), Label: n.label, ElementID: n.elementID(), Properties: n.properties()}
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// GetRelationships Связи по фильтру, упорядоченные по типу, источнику, метке и id назначения.
+func (r *UserMemoryRepo) GetRelationships(_ context.Context, filter models.RelationshipFilter) ([]models.Relationship, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	relationships := make([]models.Relationship, 0)
+	for _, e := range r.edges {
+		rel := r.relationship(e)
+
+		switch {
+		case filter.Type != "" && filter.Type != rel.Type,
+			filter.SourceID != 0 && filter.SourceID != rel.SourceID,
+			filter.TargetID != 0 && filter.TargetID != rel.TargetID,
+			filter.TargetLabel != "" && filter.TargetLabel != rel.TargetLabel:
+			continue
+		}
+
+		relationships = append(relationships, rel)
+	}
+
+	sortRelationships(relationships)
+	return relationships, nil
+}
+
+func (r *UserMemoryRepo) relationship(e edge) models.Relationship {
+	from, to := r.nodes[e.from], r.nodes[e.to]
+	return models.Relationship{Type: e.relType, SourceID: from.vkID(), TargetID: to.vkID(), TargetLabel: to.label}
+}
+
+// sortRelationships Порядок UserNeo4jRepo: по типу, источнику, метке и id назначения.
+func sortRelationships(relationships []models.Relationship) {
+	slices.SortFunc(relationships, func(a, b models.Relationship) int {
+		return cmp.Or(
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.SourceID, b.SourceID),
+			cmp.Compare(a.TargetLabel, b.TargetLabel),
+			cmp.Compare(a.TargetID, b.TargetID),
+		)
+	})
+}
+
+// DeleteRelationship Удаляет одну связь. false - если связи нет.
+func (r *UserMemoryRepo) DeleteRelationship(_ context.Context, rel models.Relationship) (bool, error) {
+	if err := validateRelationships([]models.Relationship{rel}); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets := r.users
+	if rel.TargetLabel == models.LabelGroup {
+		targets = r.groups
+	}
+
+	from, ok := r.users[rel.SourceID]
+	if !ok {
+		return false, nil
+	}
+
+	to, ok := targets[rel.TargetID]
+	if !ok {
+		return false, nil
+	}
+
+	e := edge{relType: rel.Type, from: from, to: to}
+	if _, ok := r.edgeSet[e]; !ok {
+		return false, nil
+	}
+
+	delete(r.edgeSet, e)
+	r.edges = slices.DeleteFunc(r.edges, func(other edge) bool {
+		return other == e
+	})
+
+	return true, nil
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// GetUsers Все пользователи без связей в порядке VK id.
+func (r *UserMemoryRepo) GetUsers(context.Context) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, id := range r.users {
+		users = append(users, r.nodes[id].user)
+	}
+
+	return sortUsers(users), nil
+}
+
+// GetGroups Все группы в порядке VK id.
+func (r *UserMemoryRepo) GetGroups(context.Context) ([]models.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make([]models.Group, 0, len(r.groups))
+	for _, id := range r.groups {
+		groups = append(groups, r.nodes[id].group)
+	}
+
+	return sortGroups(groups), nil
+}
+
+// FindUser Пользователь без связей, nil - если его нет.
+func (r *UserMemoryRepo) FindUser(_ context.Context, id uint64) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	user := r.nodes[n].user
+	return &user, nil
+}
+
+// FindGroup Группа без подписчиков, nil - если ее нет.
+func (r *UserMemoryRepo) FindGroup(_ context.Context, id uint64) (*models.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := r.groups[id]
+	if !ok {
+		return nil, nil
+	}
+
+	group := r.nodes[n].group
+	return &group, nil
+}
+
+// UpdateUser Перезаписывает свойства существующего пользователя. false - если пользователя нет.
+func (r *UserMemoryRepo) UpdateUser(_ context.Context, user models.User) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return false, nil
+	}
+
+	r.mergeUser(user)
+	return true, nil
+}
+
+// UpdateGroup Перезаписывает свойства существующей группы. false - если группы нет.
+func (r *UserMemoryRepo) UpdateGroup(_ context.Context, group models.Group) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.groups[group.ID]
+	if !ok {
+		return false, nil
+	}
+
+	r.nodes[id].group = group
+	return true, nil
+}
+
+// GetFollowers Подписчики пользователя: (f:User)-[:Follow]->(u).
+func (r *UserMemoryRepo) GetFollowers(_ context.Context, userID uint64) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sources(models.RelationshipFollow, r.users, userID), nil
+}
+
+// GetSubscribers Подписчики группы: (u:User)-[:Subscribe]->(g).
+func (r *UserMemoryRepo) GetSubscribers(_ context.Context, groupID uint64) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sources(models.RelationshipSubscribe, r.groups, groupID), nil
+}
+
+// sources Пользователи со связью relType, входящей в узел vkID из индекса index.
+func (r *UserMemoryRepo) sources(relType string, index map[uint64]int64, vkID uint64) []models.User {
+	users := make([]models.User, 0)
+
+	id, ok := index[vkID]
+	if !ok {
+		return users
+	}
+
+	for _, e := range r.edges {
+		if e.relType == relType && e.to == id && r.nodes[e.from].label == models.LabelUser {
+			users = append(users, r.nodes[e.from].user)
+		}
+	}
+
+	return sortUsers(users)
+}
+
+// GetSubscriptions Пользователи и группы, на которые подписан пользователь.
+func (r *UserMemoryRepo) GetSubscriptions(_ context.Context, userID uint64) (models.Subscriptions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := models.Subscriptions{Users: make([]models.User, 0), Groups: make([]models.Group, 0)}
+
+	id, ok := r.users[userID]
+	if !ok {
+		return subscriptions, nil
+	}
+
+	for _, e := range r.edges {
+		if e.relType != models.RelationshipSubscribe || e.from != id {
+			continue
+		}
+
+		switch m := r.nodes[e.to]; m.label {
+		case models.LabelUser:
+			subscriptions.Users = append(subscriptions.Users, m.user)
+		case models.LabelGroup:
+			subscriptions.Groups = append(subscriptions.Groups, m.group)
+		}
+	}
+
+	sortUsers(subscriptions.Users)
+	sortGroups(subscriptions.Groups)
+
+	return subscriptions, nil
+}
+
+func sortUsers(users []models.User) []models.User {
+	slices.SortFunc(users, func(a, b models.User) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return users
+}
+
+func sortGroups(groups []models.Group) []models.Group {
+	slices.SortFunc(groups, func(a, b models.Group) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return groups
+}
@@ -0,0 +1,227 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/rs/zerolog/log"
+	"slices"
+)
+
+// DefaultBatchSize Число элементов в одном UNWIND запросе.
+const DefaultBatchSize = 1000
+
+type Option func(*UserNeo4jRepo)
+
+// WithBatchSize Размер пачки для пакетной записи.
+func WithBatchSize(batchSize int) Option {
+	return func(r *UserNeo4jRepo) {
+		if batchSize > 0 {
+			r.batchSize = batchSize
+		}
+	}
+}
+
+const (
+	usersQuery = `
+		UNWIND $rows AS row
+		MERGE (u:User {id: row.id})
+		SET u.screen_name = row.screen_name, u.name = row.name, u.sex = row.sex, u.city = row.city
+	`
+	groupsQuery = `
+		UNWIND $rows AS row
+		MERGE (g:Group {id: row.id})
+		SET g.name = row.name, g.screen_name = row.screen_name
+	`
+	// stubQuery MERGE без SET: узел создается только с id, существующий не меняется.
+	stubQuery = `
+		UNWIND $rows AS row
+		MERGE (:%s {id: row.id})
+	`
+	relationshipsQuery = `
+		UNWIND $rows AS row
+		MATCH (s:User {id: row.source}), (t:%s {id: row.target})
+		MERGE (s)-[:%s]->(t)
+	`
+)
+
+// batchWriter Выполняет один UNWIND запрос с параметром $rows.
+type batchWriter func(ctx context.Context, query string, rows []interface{}) error
+
+// CreateUsers Создает или обновляет пользователей пачками через UNWIND, каждую пачку в отдельной транзакции.
+func (r *UserNeo4jRepo) CreateUsers(ctx context.Context, users []models.User) error {
+	return writeBatches(ctx, r.writeBatch, r.batchSize, "users", usersQuery, users, userRow)
+}
+
+// CreateGroups Создает или обновляет группы пачками через UNWIND, каждую пачку в отдельной транзакции.
+func (r *UserNeo4jRepo) CreateGroups(ctx context.Context, groups []models.Group) error {
+	return writeBatches(ctx, r.writeBatch, r.batchSize, "groups", groupsQuery, groups, groupRow)
+}
+
+// CreateStubNodes Создает узлы label только с id пачками через UNWIND, каждую пачку в отдельной транзакции.
+// Существующие узлы не меняются.
+func (r *UserNeo4jRepo) CreateStubNodes(ctx context.Context, label string, ids []uint64) error {
+	if label != models.LabelUser && label != models.LabelGroup {
+		return fmt.Errorf("write stub nodes: unsupported node label %q", label)
+	}
+
+	return writeBatches(ctx, r.writeBatch, r.batchSize, "stub nodes", fmt.Sprintf(stubQuery, label), ids, stubRow)
+}
+
+// CreateRelationships Создает связи пачками через UNWIND, каждую пачку в отдельной транзакции.
+// Связи с отсутствующими узлами пропускаются.
+func (r *UserNeo4jRepo) CreateRelationships(ctx context.Context, relationships []models.Relationship) error {
+	if err := validateRelationships(relationships); err != nil {
+		return err
+	}
+
+	return writeRelationships(ctx, r.writeBatch, r.batchSize, relationships)
+}
+
+// SaveGraph Записывает узлы и связи в одной управляемой транзакции: применяется все или ничего.
+// При временных ошибках Neo4j драйвер повторяет транзакцию целиком, поэтому запись построена на MERGE.
+func (r *UserNeo4jRepo) SaveGraph(ctx context.Context, graph models.Graph) error {
+	if err := validateRelationships(graph.Relationships); err != nil {
+		return err
+	}
+
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		write := func(ctx context.Context, query string, rows []interface{}) error {
+			_, err := tx.Run(ctx, query, map[string]interface{}{"rows": rows})
+			return err
+		}
+
+		if err := writeBatches(ctx, write, r.batchSize, "users", usersQuery, graph.Users, userRow); err != nil {
+			return nil, err
+		}
+
+		if err := writeBatches(ctx, write, r.batchSize, "groups", groupsQuery, graph.Groups, groupRow); err != nil {
+			return nil, err
+		}
+
+		stubUsersQuery := fmt.Sprintf(stubQuery, models.LabelUser)
+		if err := writeBatches(ctx, write, r.batchSize, "stub users", stubUsersQuery, graph.StubUsers, stubRow); err != nil {
+			return nil, err
+		}
+
+		stubGroupsQuery := fmt.Sprintf(stubQuery, models.LabelGroup)
+		if err := writeBatches(ctx, write, r.batchSize, "stub groups", stubGroupsQuery, graph.StubGroups, stubRow); err != nil {
+			return nil, err
+		}
+
+		return nil, writeRelationships(ctx, write, r.batchSize, graph.Relationships)
+	})
+
+	return err
+}
+
+func userRow(user models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          user.ID,
+		"screen_name": user.ScreenName,
+		"name":        user.FirstName + " " + user.LastName,
+		"sex":         user.Sex,
+		"city":        user.City.Title,
+	}
+}
+
+func groupRow(group models.Group) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          group.ID,
+		"name":        group.Name,
+		"screen_name": group.ScreenName,
+	}
+}
+
+func stubRow(id uint64) map[string]interface{} {
+	return map[string]interface{}{"id": id}
+}
+
+func relationshipRow(rel models.Relationship) map[string]interface{} {
+	return map[string]interface{}{
+		"source": rel.SourceID,
+		"target": rel.TargetID,
+	}
+}
+
+type relationshipKind struct {
+	relType     string
+	targetLabel string
+}
+
+// relationshipKinds Допустимые сочетания типа связи и метки узла назначения.
+// Тип связи и метку нельзя передать параметром, поэтому запрос собирается только из этого списка.
+var relationshipKinds = []relationshipKind{
+	{models.RelationshipFollow, models.LabelUser},
+	{models.RelationshipSubscribe, models.LabelUser},
+	{models.RelationshipSubscribe, models.LabelGroup},
+}
+
+func validateRelationships(relationships []models.Relationship) error {
+	for _, rel := range relationships {
+		if !slices.Contains(relationshipKinds, relationshipKind{rel.Type, rel.TargetLabel}) {
+			return fmt.Errorf("unsupported relationship (:User)-[:%s]->(:%s)", rel.Type, rel.TargetLabel)
+		}
+	}
+
+	return nil
+}
+
+func writeRelationships(ctx context.Context, write batchWriter, batchSize int, relationships []models.Relationship) error {
+	for _, kind := range relationshipKinds {
+		var rels []models.Relationship
+		for _, rel := range relationships {
+			if rel.Type == kind.relType && rel.TargetLabel == kind.targetLabel {
+				rels = append(rels, rel)
+			}
+		}
+
+		query := fmt.Sprintf(relationshipsQuery, kind.targetLabel, kind.relType)
+		if err := writeBatches(ctx, write, batchSize, kind.relType, query, rels, relationshipRow); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeBatches Выполняет query для items пачками по batchSize.
+func writeBatches[T any](ctx context.Context, write batchWriter, batchSize int, name, query string, items []T,
+	row func(T) map[string]interface{}) error {
+	for batch := range slices.Chunk(items, batchSize) {
+		rows := make([]interface{}, 0, len(batch))
+		for _, item := range batch {
+			rows = append(rows, row(item))
+		}
+
+		log.Debug().Msgf("Пакетная запись %s: %d", name, len(rows))
+		if err := write(ctx, query, rows); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeBatch Выполняет пачку в отдельной явной транзакции.
+func (r *UserNeo4jRepo) writeBatch(ctx context.Context, query string, rows []interface{}) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
+	tx, err := session.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close(ctx)
+
+	if _, err := tx.Run(ctx, query, map[string]interface{}{"rows": rows}); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
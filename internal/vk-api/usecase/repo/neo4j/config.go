@@ -0,0 +1,154 @@
+package neo4j
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jConfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid neo4j config")
+
+	urlSchemes  = []string{"neo4j", "neo4j+s", "neo4j+ssc", "bolt", "bolt+s", "bolt+ssc"}
+	tlsSchemes  = []string{"neo4j+s", "bolt+s"}
+	authSchemes = []string{AuthNone, AuthBasic, AuthBearer}
+)
+
+// Config Параметры подключения к Neo4j.
+type Config struct {
+	URL    string
+	DBName string
+
+	// AuthScheme Схема аутентификации: none, basic или bearer.
+	AuthScheme string
+	Username   string
+	Password   string
+	// Realm Необязательная область для basic.
+	Realm string
+	// Token Токен для bearer (например, SSO).
+	Token string
+
+	// CACertFile PEM файл с доверенными корневыми сертификатами. Только для схем neo4j+s и bolt+s.
+	CACertFile string
+
+	MaxPoolSize        int
+	AcquisitionTimeout time.Duration
+	// TxRetryTime Сколько драйвер повторяет управляемую транзакцию при временных ошибках.
+	TxRetryTime time.Duration
+}
+
+// Validate Проверяет адрес, схему аутентификации, сертификаты и параметры пула.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("%w: url: %v", ErrInvalidConfig, err)
+	}
+
+	if !slices.Contains(urlSchemes, u.Scheme) {
+		return fmt.Errorf("%w: url scheme %q, use one of %v", ErrInvalidConfig, u.Scheme, urlSchemes)
+	}
+
+	if c.DBName == "" {
+		return fmt.Errorf("%w: empty database name", ErrInvalidConfig)
+	}
+
+	switch c.AuthScheme {
+	case AuthNone, "":
+	case AuthBasic:
+		if c.Username == "" || c.Password == "" {
+			return fmt.Errorf("%w: basic auth requires username and password", ErrInvalidConfig)
+		}
+	case AuthBearer:
+		if c.Token == "" {
+			return fmt.Errorf("%w: bearer auth requires token", ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: auth scheme %q, use one of %v", ErrInvalidConfig, c.AuthScheme, authSchemes)
+	}
+
+	if c.CACertFile != "" {
+		if !slices.Contains(tlsSchemes, u.Scheme) {
+			return fmt.Errorf("%w: ca cert requires one of %v url schemes", ErrInvalidConfig, tlsSchemes)
+		}
+
+		if _, err := loadCertPool(c.CACertFile); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+
+	if c.MaxPoolSize < 0 {
+		return fmt.Errorf("%w: negative max pool size %d", ErrInvalidConfig, c.MaxPoolSize)
+	}
+
+	if c.AcquisitionTimeout < 0 {
+		return fmt.Errorf("%w: negative acquisition timeout %s", ErrInvalidConfig, c.AcquisitionTimeout)
+	}
+
+	return nil
+}
+
+func (c Config) authToken() neo4j.AuthToken {
+	switch c.AuthScheme {
+	case AuthBasic:
+		return neo4j.BasicAuth(c.Username, c.Password, c.Realm)
+	case AuthBearer:
+		return neo4j.BearerAuth(c.Token)
+	}
+
+	return neo4j.NoAuth()
+}
+
+// driverConfig Настройки драйвера. Нулевые значения оставляют значения драйвера по умолчанию.
+func (c Config) driverConfig() (func(*neo4jConfig.Config), error) {
+	var tlsConfig *tls.Config
+	if c.CACertFile != "" {
+		pool, err := loadCertPool(c.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return func(dc *neo4jConfig.Config) {
+		if tlsConfig != nil {
+			dc.TlsConfig = tlsConfig
+		}
+		if c.MaxPoolSize > 0 {
+			dc.MaxConnectionPoolSize = c.MaxPoolSize
+		}
+		if c.AcquisitionTimeout > 0 {
+			dc.ConnectionAcquisitionTimeout = c.AcquisitionTimeout
+		}
+		if c.TxRetryTime > 0 {
+			dc.MaxTransactionRetryTime = c.TxRetryTime
+		}
+	}, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("ca cert: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ca cert %s: no PEM certificates found", file)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,103 @@
+package neo4j
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCACert(t *testing.T) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	return file
+}
+
+func TestConfigValidate(t *testing.T) {
+	caCert := writeCACert(t)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	valid := Config{URL: "bolt://localhost:7687", DBName: "neo4j"}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		ok     bool
+	}{
+		{"no auth", func(c *Config) {}, true},
+		{"basic", func(c *Config) { c.AuthScheme, c.Username, c.Password = AuthBasic, "neo4j", "secret" }, true},
+		{"basic without password", func(c *Config) { c.AuthScheme, c.Username = AuthBasic, "neo4j" }, false},
+		{"bearer", func(c *Config) { c.AuthScheme, c.Token = AuthBearer, "token" }, true},
+		{"bearer without token", func(c *Config) { c.AuthScheme = AuthBearer }, false},
+		{"kerberos", func(c *Config) { c.AuthScheme = "kerberos" }, false},
+		{"http url", func(c *Config) { c.URL = "http://localhost:7474" }, false},
+		{"empty database", func(c *Config) { c.DBName = "" }, false},
+		{"ca bundle", func(c *Config) { c.URL, c.CACertFile = "neo4j+s://db.example.com", caCert }, true},
+		{"ca bundle without tls", func(c *Config) { c.CACertFile = caCert }, false},
+		{"ca bundle not pem", func(c *Config) { c.URL, c.CACertFile = "neo4j+s://db.example.com", notPEM }, false},
+		{"ca bundle missing", func(c *Config) { c.URL, c.CACertFile = "neo4j+s://db.example.com", "missing.pem" }, false},
+		{"negative pool", func(c *Config) { c.MaxPoolSize = -1 }, false},
+		{"negative timeout", func(c *Config) { c.AcquisitionTimeout = -time.Second }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
+
+func TestNewUserNeo4jRepoAppliesConfig(t *testing.T) {
+	r, err := NewUserNeo4jRepo(Config{
+		URL:                "neo4j+s://db.example.com",
+		DBName:             "vk",
+		AuthScheme:         AuthBasic,
+		Username:           "neo4j",
+		Password:           "secret",
+		CACertFile:         writeCACert(t),
+		MaxPoolSize:        7,
+		AcquisitionTimeout: 3 * time.Second,
+	}, WithBatchSize(10))
+	require.NoError(t, err)
+	defer r.Close(context.Background())
+
+	require.Equal(t, "vk", r.dbName)
+	require.Equal(t, 10, r.batchSize)
+}
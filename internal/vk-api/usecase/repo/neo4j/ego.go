@@ -0,0 +1,108 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"strings"
+)
+
+// GetEgoNetwork Окрестность узла: по одному запросу на каждый шаг от центра, затем связи между узлами окрестности.
+func (r *UserNeo4jRepo) GetEgoNetwork(ctx context.Context, q models.EgoQuery) (*models.Subgraph, error) {
+	pattern, err := relationshipPattern(q.Types, q.Direction, "")
+	if err != nil {
+		return nil, err
+	}
+
+	match, params, err := matchNode(q.Center)
+	if err != nil {
+		return nil, err
+	}
+
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, match+" RETURN n", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Next(ctx) {
+		return nil, result.Err()
+	}
+
+	center, err := recordNode(result.Record(), "n")
+	if err != nil {
+		return nil, err
+	}
+
+	subgraph := &models.Subgraph{Nodes: []models.Node{processNode(center)}}
+	ids := []string{center.ElementId}
+	frontier := ids
+
+	levelQuery := fmt.Sprintf(`
+		MATCH (n)%s(m)
+		WHERE elementId(n) IN $frontier AND NOT elementId(m) IN $seen
+		WITH DISTINCT m
+		RETURN m
+		ORDER BY labels(m)[0], m.id
+		LIMIT $limit
+	`, pattern)
+	for hops := 1; hops <= q.Hops && len(frontier) > 0; hops++ {
+		remaining := q.Limit - len(subgraph.Nodes)
+
+		// Запрашивается на один узел больше, чтобы узнать, поместилась ли окрестность в лимит.
+		result, err := session.Run(ctx, levelQuery, map[string]interface{}{
+			"frontier": frontier,
+			"seen":     ids,
+			"limit":    remaining + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var level []string
+		for result.Next(ctx) {
+			if len(level) == remaining {
+				subgraph.Truncated = true
+				continue
+			}
+
+			m, err := recordNode(result.Record(), "m")
+			if err != nil {
+				return nil, err
+			}
+
+			level = append(level, m.ElementId)
+			subgraph.Nodes = append(subgraph.Nodes, processNode(m))
+		}
+
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, level...)
+		if subgraph.Truncated {
+			break
+		}
+
+		frontier = level
+	}
+
+	result, err = session.Run(ctx, `
+		MATCH (s)-[r:`+strings.Join(q.Types, "|")+`]->(t)
+		WHERE elementId(s) IN $ids AND elementId(t) IN $ids
+		RETURN type(r) AS type, s.id AS source_id, t.id AS target_id, labels(t)[0] AS target_label
+		ORDER BY type, source_id, target_label, target_id
+	`, map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	subgraph.Relationships = make([]models.Relationship, 0)
+	for result.Next(ctx) {
+		subgraph.Relationships = append(subgraph.Relationships, recordRelationship(result.Record()))
+	}
+
+	return subgraph, result.Err()
+}
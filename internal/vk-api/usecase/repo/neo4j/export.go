@@ -0,0 +1,118 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"strings"
+)
+
+// ExportGraph Узлы по фильтру в порядке метки и VK id, затем связи между ними в порядке GetRelationships.
+// Записи читаются из курсора по мере обработки.
+func (r *UserNeo4jRepo) ExportGraph(ctx context.Context, filter models.ExportFilter,
+	node func(models.Node) error, rel func(models.Relationship) error) error {
+	nodesQuery, relationshipsQuery, params, err := exportQueries(filter)
+	if err != nil {
+		return err
+	}
+
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, nodesQuery, params)
+	if err != nil {
+		return err
+	}
+
+	for result.Next(ctx) {
+		n, err := recordNode(result.Record(), "n")
+		if err != nil {
+			return err
+		}
+
+		if err := node(processNode(n)); err != nil {
+			return err
+		}
+	}
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	result, err = session.Run(ctx, relationshipsQuery, params)
+	if err != nil {
+		return err
+	}
+
+	for result.Next(ctx) {
+		if err := rel(recordRelationship(result.Record())); err != nil {
+			return err
+		}
+	}
+
+	return result.Err()
+}
+
+func exportQueries(filter models.ExportFilter) (string, string, map[string]interface{}, error) {
+	for _, relType := range filter.Types {
+		if relType != models.RelationshipFollow && relType != models.RelationshipSubscribe {
+			return "", "", nil, fmt.Errorf("unsupported relationship type %q", relType)
+		}
+	}
+
+	if len(filter.Types) == 0 {
+		return "", "", nil, fmt.Errorf("no relationship types")
+	}
+
+	params := map[string]interface{}{}
+	if filter.City != "" {
+		params["city"] = filter.City
+	}
+	if filter.Sex != nil {
+		params["sex"] = *filter.Sex
+	}
+
+	// Ошибка возможна только из-за метки и одинакова для всех узлов.
+	nodeCondition, err := exportCondition("n", filter)
+	if err != nil {
+		return "", "", nil, err
+	}
+	sourceCondition, _ := exportCondition("s", filter)
+	targetCondition, _ := exportCondition("t", filter)
+
+	nodesQuery := `
+		MATCH (n) WHERE ` + nodeCondition + `
+		RETURN n
+		ORDER BY labels(n)[0], n.id
+	`
+	relationshipsQuery := `
+		MATCH (s)-[r:` + strings.Join(filter.Types, "|") + `]->(t)
+		WHERE ` + sourceCondition + ` AND ` + targetCondition + `
+		RETURN type(r) AS type, s.id AS source_id, t.id AS target_id, labels(t)[0] AS target_label
+		ORDER BY type, source_id, target_label, target_id
+	`
+
+	return nodesQuery, relationshipsQuery, params, nil
+}
+
+// exportCondition Условие фильтра для узла v.
+func exportCondition(v string, filter models.ExportFilter) (string, error) {
+	var conditions []string
+	switch filter.Label {
+	case "":
+		conditions = append(conditions, fmt.Sprintf("(%s:User OR %s:Group)", v, v))
+	case models.LabelUser, models.LabelGroup:
+		conditions = append(conditions, v+":"+filter.Label)
+	default:
+		return "", fmt.Errorf("unsupported node label %q", filter.Label)
+	}
+
+	if filter.City != "" {
+		conditions = append(conditions, fmt.Sprintf("%s:User AND %s.city = $city", v, v))
+	}
+	if filter.Sex != nil {
+		conditions = append(conditions, fmt.Sprintf("%s:User AND %s.sex = $sex", v, v))
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", nil
+}
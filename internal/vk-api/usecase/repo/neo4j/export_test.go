@@ -0,0 +1,37 @@
+package neo4j
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExportQueries(t *testing.T) {
+	nodes, relationships, params, err := exportQueries(models.ExportFilter{
+		Types: []string{models.RelationshipFollow, models.RelationshipSubscribe},
+	})
+	require.NoError(t, err)
+	require.Contains(t, nodes, "MATCH (n) WHERE ((n:User OR n:Group))")
+	require.Contains(t, relationships, "MATCH (s)-[r:Follow|Subscribe]->(t)")
+	require.Contains(t, relationships, "WHERE ((s:User OR s:Group)) AND ((t:User OR t:Group))")
+	require.Empty(t, params)
+
+	sex := byte(1)
+	nodes, relationships, params, err = exportQueries(models.ExportFilter{
+		Label: models.LabelUser,
+		City:  "Surgut",
+		Sex:   &sex,
+		Types: []string{models.RelationshipFollow},
+	})
+	require.NoError(t, err)
+	require.Contains(t, nodes, "(n:User AND n:User AND n.city = $city AND n:User AND n.sex = $sex)")
+	require.Contains(t, relationships, "MATCH (s)-[r:Follow]->(t)")
+	require.Contains(t, relationships, "t:User AND t.city = $city")
+	require.Equal(t, map[string]interface{}{"city": "Surgut", "sex": sex}, params)
+
+	_, _, _, err = exportQueries(models.ExportFilter{Label: "Page", Types: []string{models.RelationshipFollow}})
+	require.Error(t, err)
+
+	_, _, _, err = exportQueries(models.ExportFilter{Types: []string{"Follow]->() DETACH DELETE (s"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package neo4j
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"slices"
+)
+
+// GetMutualFollowers Пользователи, подписанные на каждого из userIDs: (n:User)-[:Follow]->(u).
+func (r *UserNeo4jRepo) GetMutualFollowers(ctx context.Context, userIDs []uint64) ([]models.User, int, error) {
+	nodes, union, err := r.overlap(ctx, "MATCH (n:User)-[:Follow]->(u:User)", userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]models.User, 0, len(nodes))
+	for _, n := range nodes {
+		users = append(users, processUserNode(n))
+	}
+
+	return users, union, nil
+}
+
+// GetMutualSubscriptions Пользователи, на которых подписан каждый из userIDs: (u)-[:Subscribe]->(n:User).
+func (r *UserNeo4jRepo) GetMutualSubscriptions(ctx context.Context, userIDs []uint64) ([]models.User, int, error) {
+	nodes, union, err := r.overlap(ctx, "MATCH (u:User)-[:Subscribe]->(n:User)", userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]models.User, 0, len(nodes))
+	for _, n := range nodes {
+		users = append(users, processUserNode(n))
+	}
+
+	return users, union, nil
+}
+
+// GetCommonGroups Группы, на которые подписан каждый из userIDs: (u)-[:Subscribe]->(n:Group).
+func (r *UserNeo4jRepo) GetCommonGroups(ctx context.Context, userIDs []uint64) ([]models.Group, int, error) {
+	nodes, union, err := r.overlap(ctx, "MATCH (u:User)-[:Subscribe]->(n:Group)", userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups := make([]models.Group, 0, len(nodes))
+	for _, n := range nodes {
+		groups = append(groups, processGroupNode(n))
+	}
+
+	return groups, union, nil
+}
+
+// overlap Узлы n, связанные шаблоном match с каждым пользователем u из userIDs, в порядке VK id
+// и число узлов n, связанных хотя бы с одним из них. userIDs не должны повторяться.
+func (r *UserNeo4jRepo) overlap(ctx context.Context, match string, userIDs []uint64) ([]neo4j.Node, int, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	query := match + `
+		WHERE u.id IN $ids
+		WITH n, count(u) AS linked
+		RETURN count(n) AS total, collect(CASE WHEN linked = $size THEN n END) AS common
+	`
+	result, err := session.Run(ctx, query, map[string]interface{}{"ids": userIDs, "size": len(userIDs)})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	record, err := result.Single(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value, _ := record.Get("total")
+	total, ok := value.(int64)
+	if !ok {
+		return nil, 0, fmt.Errorf("cant assert count %+v (type %T) to int64", value, value)
+	}
+
+	common, _ := record.Get("common")
+	values, _ := common.([]interface{})
+	nodes := make([]neo4j.Node, 0, len(values))
+	for _, value := range values {
+		n, ok := value.(neo4j.Node)
+		if !ok {
+			return nil, 0, fmt.Errorf("cant assert node %+v (type %T) to neo4j.Node", value, value)
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	slices.SortFunc(nodes, func(a, b neo4j.Node) int {
+		x, _ := a.Props["id"].(int64)
+		y, _ := b.Props["id"].(int64)
+		return cmp.Compare(x, y)
+	})
+
+	return nodes, int(total), nil
+}
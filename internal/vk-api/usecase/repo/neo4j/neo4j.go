@@ -15,21 +15,60 @@ const (
 	srcTypeGroup       = 2
 )
 
+// UserNeo4jRepo Владеет драйвером Neo4j и открывает короткую сессию на каждую операцию:
+// сессии не потокобезопасны, а репозиторий используется всеми HTTP запросами одновременно.
 type UserNeo4jRepo struct {
-	session neo4j.SessionWithContext
+	driver    neo4j.DriverWithContext
+	dbName    string
+	batchSize int
 }
 
-func NewUserNeo4jRepo(session neo4j.SessionWithContext) *UserNeo4jRepo {
-	return &UserNeo4jRepo{session: session}
+func NewUserNeo4jRepo(cfg Config, opts ...Option) (*UserNeo4jRepo, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	driverConfig, err := cfg.driverConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := neo4j.NewDriverWithContext(cfg.URL, cfg.authToken(), driverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("neo4j.NewDriverWithContext: %w", err)
+	}
+
+	r := &UserNeo4jRepo{driver: driver, dbName: cfg.DBName, batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Close Закрывает драйвер вместе с пулом соединений.
+func (r *UserNeo4jRepo) Close(ctx context.Context) error {
+	return r.driver.Close(ctx)
+}
+
+func (r *UserNeo4jRepo) readSession(ctx context.Context) neo4j.SessionWithContext {
+	return r.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: r.dbName, AccessMode: neo4j.AccessModeRead})
+}
+
+func (r *UserNeo4jRepo) writeSession(ctx context.Context) neo4j.SessionWithContext {
+	return r.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: r.dbName, AccessMode: neo4j.AccessModeWrite})
 }
 
 func (r *UserNeo4jRepo) CreateIndexes(ctx context.Context) error {
-	_, err := r.session.Run(ctx, "CREATE CONSTRAINT FOR (u:User) REQUIRE u.id IS UNIQUE", map[string]interface{}{})
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
+	_, err := session.Run(ctx, "CREATE CONSTRAINT FOR (u:User) REQUIRE u.id IS UNIQUE", map[string]interface{}{})
 	if err != nil {
 		return err
 	}
 
-	_, err = r.session.Run(ctx, "CREATE CONSTRAINT FOR (u:Group) REQUIRE u.id IS UNIQUE", map[string]interface{}{})
+	_, err = session.Run(ctx, "CREATE CONSTRAINT FOR (u:Group) REQUIRE u.id IS UNIQUE", map[string]interface{}{})
 	if err != nil {
 		return err
 	}
@@ -38,8 +77,11 @@ func (r *UserNeo4jRepo) CreateIndexes(ctx context.Context) error {
 }
 
 func (r *UserNeo4jRepo) CreateUser(ctx context.Context, user models.User) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
 	log.Debug().Msgf("Создание пользователя %s", user.FirstName+" "+user.LastName)
-	_, err := r.session.Run(ctx,
+	_, err := session.Run(ctx,
 		"MERGE (u:User {id: $id}) "+
 			"SET u.screen_name = $screen_name, u.name = $name, u.sex = $sex, u.city = $city",
 		map[string]interface{}{
@@ -54,8 +96,11 @@ func (r *UserNeo4jRepo) CreateUser(ctx context.Context, user models.User) error
 }
 
 func (r *UserNeo4jRepo) CreateGroup(ctx context.Context, group models.Group) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
 	log.Debug().Msgf("Создание группы %+v", group.Name)
-	_, err := r.session.Run(ctx,
+	_, err := session.Run(ctx,
 		"MERGE (g:Group {id: $id}) "+
 			"SET g.name = $name, g.screen_name = $screen_name ",
 		map[string]interface{}{
@@ -68,8 +113,11 @@ func (r *UserNeo4jRepo) CreateGroup(ctx context.Context, group models.Group) err
 }
 
 func (r *UserNeo4jRepo) CreateFollowRelationship(ctx context.Context, follower models.User, followee models.User) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
 	log.Debug().Msgf("Создание фоллов связи follower: %+v - followee: %+v", follower.FirstName+" "+follower.LastName, followee.FirstName+" "+followee.LastName)
-	_, err := r.session.Run(ctx,
+	_, err := session.Run(ctx,
 		"MATCH (f:User {id: $followerId}), (e:User {id: $followeeId}) "+
 			"MERGE (f)-[:Follow]->(e)",
 		map[string]interface{}{
@@ -81,8 +129,11 @@ func (r *UserNeo4jRepo) CreateFollowRelationship(ctx context.Context, follower m
 }
 
 func (r *UserNeo4jRepo) CreateSubscribeUserUserRelationship(ctx context.Context, subscriber models.User, subscribed models.User) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
 	log.Debug().Msgf("Создание subscribe связи subscriber: %+v - subscribed: %+v", subscriber.FirstName+" "+subscriber.LastName, subscribed.FirstName+" "+subscribed.LastName)
-	_, err := r.session.Run(ctx,
+	_, err := session.Run(ctx,
 		"MATCH (s:User {id: $subscriberId}), (u:User {id: $subscribedId}) "+
 			"MERGE (s)-[:Subscribe]->(u)",
 		map[string]interface{}{
@@ -94,8 +145,11 @@ func (r *UserNeo4jRepo) CreateSubscribeUserUserRelationship(ctx context.Context,
 }
 
 func (r *UserNeo4jRepo) CreateSubscribeUserGroupRelationship(ctx context.Context, user models.User, group models.Group) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
 	log.Debug().Msgf("Создание связи user: %+v - group: %+v", user.FirstName+" "+user.LastName, group.Name)
-	_, err := r.session.Run(ctx,
+	_, err := session.Run(ctx,
 		"MATCH (u:User {id: $userId}), (g:Group {id: $groupId}) "+
 			"MERGE (u)-[:Subscribe]->(g)",
 		map[string]interface{}{
@@ -106,18 +160,38 @@ func (r *UserNeo4jRepo) CreateSubscribeUserGroupRelationship(ctx context.Context
 	return err
 }
 
-func (r *UserNeo4jRepo) DeleteNode(ctx context.Context, id uint64) error {
-	query := `
-		MATCH (n)
-		WHERE id(n) = $nodeId
-		DETACH DELETE n
-	`
-	_, err := r.session.Run(ctx, query, map[string]interface{}{"nodeId": id})
+func (r *UserNeo4jRepo) DeleteNode(ctx context.Context, ref models.NodeRef) error {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
+	match, params, err := matchNode(ref)
+	if err != nil {
+		return err
+	}
+
+	_, err = session.Run(ctx, match+" DETACH DELETE n", params)
 	return err
 }
 
+// matchNode MATCH для узла n по ссылке. Метку нельзя передать параметром, поэтому допускаются только User и Group.
+func matchNode(ref models.NodeRef) (string, map[string]interface{}, error) {
+	if ref.ElementID != "" {
+		return "MATCH (n) WHERE elementId(n) = $elementId", map[string]interface{}{"elementId": ref.ElementID}, nil
+	}
+
+	switch ref.Label {
+	case models.LabelUser, models.LabelGroup:
+		return fmt.Sprintf("MATCH (n:%s {id: $id})", ref.Label), map[string]interface{}{"id": ref.ID}, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported node label %q", ref.Label)
+}
+
 func (r *UserNeo4jRepo) GetUsersCount(ctx context.Context) (int, error) {
-	result, err := r.session.Run(ctx, "MATCH (u:User) RETURN COUNT(u) AS count", nil)
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "MATCH (u:User) RETURN COUNT(u) AS count", nil)
 	if err != nil {
 		return 0, err
 	}
@@ -138,7 +212,10 @@ func (r *UserNeo4jRepo) GetUsersCount(ctx context.Context) (int, error) {
 }
 
 func (r *UserNeo4jRepo) GetGroupsCount(ctx context.Context) (int, error) {
-	result, err := r.session.Run(ctx, "MATCH (u:Group) RETURN COUNT(u) AS count", nil)
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "MATCH (u:Group) RETURN COUNT(u) AS count", nil)
 	if err != nil {
 		return 0, err
 	}
@@ -158,174 +235,128 @@ func (r *UserNeo4jRepo) GetGroupsCount(ctx context.Context) (int, error) {
 	return 0, nil
 }
 
-func (r *UserNeo4jRepo) GetTopUsersByFollowersCount(ctx context.Context, limit int) ([]models.User, error) {
+// GetTopUsersByFollowersCount Пользователи с наибольшим числом подписчиков, при равенстве по VK id.
+func (r *UserNeo4jRepo) GetTopUsersByFollowersCount(ctx context.Context, limit int) ([]models.TopUser, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
 	query := `
 		MATCH (u:User)<-[:Follow]-(f:User)
-		RETURN u.id AS id, u.screen_name AS screen_name, u.name AS name, u.sex AS sex, u.city AS city, COUNT(f) AS followersCount 
-		ORDER BY followersCount DESC
+		RETURN u, COUNT(f) AS followersCount
+		ORDER BY followersCount DESC, u.id
 		LIMIT $limit
 	`
-	result, err := r.session.Run(ctx, query, map[string]interface{}{"limit": limit})
+	result, err := session.Run(ctx, query, map[string]interface{}{"limit": limit})
 	if err != nil {
 		return nil, err
 	}
 
-	var users []models.User
+	var users []models.TopUser
 	for result.Next(ctx) {
 		record := result.Record()
-		id, _ := record.Get("id")
-		screenName, _ := record.Get("screen_name")
-		name, _ := record.Get("name")
-		sex, _ := record.Get("sex")
-		city, _ := record.Get("city")
-
-		nameParts := strings.Split(name.(string), " ")
-		var firstName, lastName string
-		if len(nameParts) > 0 {
-			firstName = nameParts[0]
-		}
-		if len(nameParts) > 1 {
-			lastName = nameParts[1]
+		u, _ := record.Get("u")
+		node, ok := u.(neo4j.Node)
+		if !ok {
+			return nil, fmt.Errorf("unexpected user value %T", u)
 		}
+		count, _ := record.Get("followersCount")
 
-		users = append(users, models.User{
-			ID:         uint64(id.(int64)),
-			ScreenName: screenName.(string),
-			FirstName:  firstName,
-			LastName:   lastName,
-			Sex:        byte(sex.(int64)),
-			City: models.City{
-				Title: city.(string),
-			},
+		users = append(users, models.TopUser{
+			User:           processUserNode(node),
+			FollowersCount: int(count.(int64)),
 		})
 	}
 
-	return users, nil
+	return users, result.Err()
 }
 
-func (r *UserNeo4jRepo) GetTopGroupsBySubscribersCount(ctx context.Context, limit int) ([]models.Group, error) {
+// GetTopGroupsBySubscribersCount Группы с наибольшим числом подписчиков-пользователей, при равенстве по VK id.
+func (r *UserNeo4jRepo) GetTopGroupsBySubscribersCount(ctx context.Context, limit int) ([]models.TopGroup, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
 	query := `
 		MATCH (g:Group)<-[:Subscribe]-(u:User)
-		RETURN g.id AS group_id, g.name AS name, g.screen_name AS screen_name, COUNT(u) AS subscribersCount 
-		ORDER BY subscribersCount DESC
+		RETURN g, COUNT(u) AS subscribersCount
+		ORDER BY subscribersCount DESC, g.id
 		LIMIT $limit
 	`
-	result, err := r.session.Run(ctx, query, map[string]interface{}{"limit": limit})
+	result, err := session.Run(ctx, query, map[string]interface{}{"limit": limit})
 	if err != nil {
 		return nil, err
 	}
 
-	var groups []models.Group
+	var groups []models.TopGroup
 	for result.Next(ctx) {
 		record := result.Record()
-		groupID, _ := record.Get("group_id")
-		name, _ := record.Get("name")
-		screenName, _ := record.Get("screen_name")
-
-		groups = append(groups, models.Group{
-			ID:         uint64(groupID.(int64)),
-			Name:       name.(string),
-			ScreenName: screenName.(string),
+		g, _ := record.Get("g")
+		node, ok := g.(neo4j.Node)
+		if !ok {
+			return nil, fmt.Errorf("unexpected group value %T", g)
+		}
+		count, _ := record.Get("subscribersCount")
+
+		groups = append(groups, models.TopGroup{
+			Group:            processGroupNode(node),
+			SubscribersCount: int(count.(int64)),
 		})
 	}
 
-	return groups, nil
+	return groups, result.Err()
 }
 
+// GetUsersWithDifferentGroups Пользователи с подписками на группы, ни на одну из которых не подписан
+// другой пользователь, по VK id.
 func (r *UserNeo4jRepo) GetUsersWithDifferentGroups(ctx context.Context, limit int) ([]models.User, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
 	query := `
-		MATCH (g:Group)<-[:Subscribe]-(u:User)
-		WITH u
-		MATCH (g)<-[:Subscribe]-(other:User)
-		WHERE u <> other
-		RETURN DISTINCT u.id AS id, u.screen_name AS screen_name, u.name AS name, u.sex AS sex, u.city AS city
-		LIMIT $limit;
+		MATCH (u:User)-[:Subscribe]->(:Group)
+		WHERE NOT EXISTS { (u)-[:Subscribe]->(:Group)<-[:Subscribe]-(other:User) WHERE other <> u }
+		WITH DISTINCT u
+		RETURN u
+		ORDER BY u.id
+		LIMIT $limit
 	`
-	result, err := r.session.Run(ctx, query, map[string]interface{}{"limit": limit})
+	result, err := session.Run(ctx, query, map[string]interface{}{"limit": limit})
 	if err != nil {
 		return nil, err
 	}
 
 	var users []models.User
 	for result.Next(ctx) {
-		record := result.Record()
-		id, _ := record.Get("id")
-		screenName, _ := record.Get("screen_name")
-		name, _ := record.Get("name")
-		sex, _ := record.Get("sex")
-		city, _ := record.Get("city")
-
-		nameParts := strings.Split(name.(string), " ")
-		var firstName, lastName string
-		if len(nameParts) > 0 {
-			firstName = nameParts[0]
-		}
-		if len(nameParts) > 1 {
-			lastName = nameParts[1]
+		u, _ := result.Record().Get("u")
+		node, ok := u.(neo4j.Node)
+		if !ok {
+			return nil, fmt.Errorf("unexpected user value %T", u)
 		}
 
-		users = append(users, models.User{
-			ID:         uint64(id.(int64)),
-			ScreenName: screenName.(string),
-			FirstName:  firstName,
-			LastName:   lastName,
-			Sex:        byte(sex.(int64)),
-			City: models.City{
-				Title: city.(string),
-			},
-		})
+		users = append(users, processUserNode(node))
 	}
 
-	return users, nil
+	return users, result.Err()
 }
 
-func (r *UserNeo4jRepo) GetAllNodes(ctx context.Context) ([]models.Node, error) {
-	query := `
-		MATCH (n)
-		RETURN id(n) AS id, labels(n) AS labels
-	`
-	result, err := r.session.Run(ctx, query, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var nodes []models.Node
-	for result.Next(ctx) {
-		record := result.Record()
-		nodeID, _ := record.Get("id")
-		nodeLabels, _ := record.Get("labels")
-
-		var labels []string
-		for _, label := range nodeLabels.([]interface{}) {
-			labels = append(labels, label.(string))
-		}
-
-		nodes = append(nodes, models.Node{
-			ID:     nodeID.(int64),
-			Labels: labels,
-		})
-	}
+// GetNodeWithRelationships Возвращает models.User или models.GroupWithSubscribers, nil - если узла нет.
+func (r *UserNeo4jRepo) GetNodeWithRelationships(ctx context.Context, ref models.NodeRef) (interface{}, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
 
-	if err = result.Err(); err != nil {
+	match, params, err := matchNode(ref)
+	if err != nil {
 		return nil, err
 	}
 
-	return nodes, nil
-}
-
-func (r *UserNeo4jRepo) GetNodeWithRelationships(ctx context.Context, id uint64) (interface{}, error) {
-	query := `
-		OPTIONAL MATCH (n)-[r]->(m) WHERE id(n) = $nodeID 
-		WITH n, r, m 
-		WHERE n IS NOT NULL  
-		RETURN n, r, m, null as r2 
+	query := match + `
+		OPTIONAL MATCH (n)-[r]->(m)
+		RETURN n, r, m, null as r2
 		UNION
-		OPTIONAL MATCH (n)<-[r2]-(m) WHERE id(n) = $nodeID 
-		WITH n, r2, m 
-		WHERE n IS NOT NULL  
-		RETURN n, null as r, m, r2 
+		` + match + `
+		OPTIONAL MATCH (n)<-[r2]-(m)
+		RETURN n, null as r, m, r2
 	`
-	result, err := r.session.Run(ctx, query, map[string]interface{}{"nodeID": id})
+	result, err := session.Run(ctx, query, params)
 	if err != nil {
 		return nil, err
 	}
@@ -358,7 +389,12 @@ func (r *UserNeo4jRepo) GetNodeWithRelationships(ctx context.Context, id uint64)
 			}
 		}
 
+		// Узел без связей приходит одной строкой с m = null.
 		connectedNode, _ := record.Get("m")
+		if connectedNode == nil {
+			continue
+		}
+
 		m, ok := connectedNode.(neo4j.Node)
 		if !ok {
 			return nil, fmt.Errorf("cant assert node %+v (type %T) to neo4j.Node", connectedNode, connectedNode)
@@ -0,0 +1,142 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"strings"
+)
+
+// GetNodes Страница узлов по запросу. Курсор указывает на последний узел страницы, nil - если страница последняя.
+func (r *UserNeo4jRepo) GetNodes(ctx context.Context, q models.NodeQuery) ([]models.Node, *models.NodeCursor, error) {
+	query, params, err := nodesQuery(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]models.Node, 0, q.Limit)
+	var names []string
+	for result.Next(ctx) {
+		record := result.Record()
+		nodeID, _ := record.Get("id")
+		label, _ := record.Get("label")
+		elementID, _ := record.Get("element_id")
+		name, _ := record.Get("name")
+		properties, _ := record.Get("properties")
+
+		node := models.Node{}
+		node.Label, _ = label.(string)
+		node.ElementID, _ = elementID.(string)
+		if id, ok := nodeID.(int64); ok {
+			node.ID = uint64(id)
+		}
+		if props, ok := properties.(map[string]interface{}); ok {
+			node.Properties = props
+		}
+
+		nodes = append(nodes, node)
+		names = append(names, name.(string))
+	}
+
+	if err = result.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Запрашивается на один узел больше, чтобы узнать, есть ли следующая страница.
+	if len(nodes) <= q.Limit {
+		return nodes, nil, nil
+	}
+
+	nodes = nodes[:q.Limit]
+	last := nodes[len(nodes)-1]
+	cursor := &models.NodeCursor{Sort: q.Sort, Desc: q.Desc, Label: last.Label, ID: last.ID}
+	if q.Sort == models.NodeSortName {
+		cursor.Name = names[q.Limit-1]
+	}
+
+	return nodes, cursor, nil
+}
+
+func nodesQuery(q models.NodeQuery) (string, map[string]interface{}, error) {
+	params := map[string]interface{}{"limit": q.Limit + 1, "withProperties": q.WithProperties}
+
+	var match string
+	switch q.Label {
+	case "":
+		match = "MATCH (n) WHERE n:User OR n:Group"
+	case models.LabelUser, models.LabelGroup:
+		match = fmt.Sprintf("MATCH (n:%s)", q.Label)
+	default:
+		return "", nil, fmt.Errorf("unsupported node label %q", q.Label)
+	}
+
+	var where []string
+	if q.City != "" {
+		where = append(where, "n:User AND n.city = $city")
+		params["city"] = q.City
+	}
+	if q.Sex != nil {
+		where = append(where, "n:User AND n.sex = $sex")
+		params["sex"] = *q.Sex
+	}
+	if q.NamePrefix != "" {
+		where = append(where, "toLower(name) STARTS WITH toLower($namePrefix)")
+		params["namePrefix"] = q.NamePrefix
+	}
+
+	// Ключи сортировки однозначно задают позицию узла: у пользователя и группы VK id могут совпадать.
+	keys, afterParams := []string{"n.id", "label"}, []string{"$afterID", "$afterLabel"}
+	if q.Sort == models.NodeSortName {
+		keys, afterParams = []string{"name", "label", "n.id"}, []string{"$afterName", "$afterLabel", "$afterID"}
+	}
+
+	if q.After != nil {
+		params["afterName"] = q.After.Name
+		params["afterLabel"] = q.After.Label
+		params["afterID"] = q.After.ID
+
+		op := ">"
+		if q.Desc {
+			op = "<"
+		}
+		where = append(where, keysetCondition(keys, afterParams, op))
+	}
+
+	order := strings.Join(keys, ", ")
+	if q.Desc {
+		order = strings.Join(keys, " DESC, ") + " DESC"
+	}
+
+	query := match + `
+		WITH n, labels(n)[0] AS label, coalesce(n.name, '') AS name
+	`
+	if len(where) > 0 {
+		query += "WHERE (" + strings.Join(where, ") AND (") + ")"
+	}
+	query += `
+		RETURN n.id AS id, label, elementId(n) AS element_id, name,
+			CASE WHEN $withProperties THEN properties(n) END AS properties
+		ORDER BY ` + order + `
+		LIMIT $limit
+	`
+
+	return query, params, nil
+}
+
+// keysetCondition Лексикографическое сравнение (keys) op (params): k1 > p1 OR (k1 = p1 AND (k2 > p2 OR ...)).
+func keysetCondition(keys, params []string, op string) string {
+	condition := keys[len(keys)-1] + " " + op + " " + params[len(params)-1]
+	for i := len(keys) - 2; i >= 0; i-- {
+		condition = fmt.Sprintf("%s %s %s OR (%s = %s AND (%s))", keys[i], op, params[i], keys[i], params[i], condition)
+	}
+
+	return condition
+}
@@ -0,0 +1,43 @@
+package neo4j
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	require.Equal(t,
+		"n.id > $afterID OR (n.id = $afterID AND (label > $afterLabel))",
+		keysetCondition([]string{"n.id", "label"}, []string{"$afterID", "$afterLabel"}, ">"),
+	)
+}
+
+func TestNodesQuery(t *testing.T) {
+	sex := byte(2)
+	query, params, err := nodesQuery(models.NodeQuery{
+		Label:      models.LabelUser,
+		Sex:        &sex,
+		NamePrefix: "iv",
+		Sort:       models.NodeSortName,
+		Desc:       true,
+		Limit:      10,
+		After:      &models.NodeCursor{Sort: models.NodeSortName, Desc: true, Name: "Ivan", Label: models.LabelUser, ID: 7},
+	})
+	require.NoError(t, err)
+	require.Contains(t, query, "MATCH (n:User)")
+	require.Contains(t, query, "name < $afterName OR (name = $afterName AND (label < $afterLabel OR (label = $afterLabel AND (n.id < $afterID))))")
+	require.Contains(t, query, "ORDER BY name DESC, label DESC, n.id DESC")
+	require.Equal(t, 11, params["limit"])
+	require.Equal(t, sex, params["sex"])
+	require.Equal(t, "Ivan", params["afterName"])
+
+	query, _, err = nodesQuery(models.NodeQuery{Sort: models.NodeSortID, Limit: 10})
+	require.NoError(t, err)
+	require.Contains(t, query, "MATCH (n) WHERE n:User OR n:Group")
+	require.Contains(t, query, "ORDER BY n.id, label")
+	require.NotContains(t, query, "$afterID")
+
+	_, _, err = nodesQuery(models.NodeQuery{Label: "Post) DETACH DELETE (n", Limit: 10})
+	require.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"strings"
+)
+
+// FindShortestPaths Кратчайшие пути между пользователями. Пустой результат - если пути не длиннее MaxHops нет.
+func (r *UserNeo4jRepo) FindShortestPaths(ctx context.Context, q models.PathQuery) ([]models.Path, error) {
+	query, err := pathsQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"source": q.SourceID,
+		"target": q.TargetID,
+		"limit":  q.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]models.Path, 0)
+	for result.Next(ctx) {
+		value, _ := result.Record().Get("p")
+		p, ok := value.(neo4j.Path)
+		if !ok {
+			return nil, fmt.Errorf("cant assert path %+v (type %T) to neo4j.Path", value, value)
+		}
+
+		paths = append(paths, processPath(p))
+	}
+
+	return paths, result.Err()
+}
+
+func pathsQuery(q models.PathQuery) (string, error) {
+	if q.MaxHops < 1 {
+		return "", fmt.Errorf("path needs max hops")
+	}
+
+	pattern, err := relationshipPattern(q.Types, q.Direction, fmt.Sprintf("*..%d", q.MaxHops))
+	if err != nil {
+		return "", err
+	}
+
+	shortest := "shortestPath"
+	if q.All {
+		shortest = "allShortestPaths"
+	}
+
+	query := fmt.Sprintf(`
+		MATCH (a:User {id: $source}), (b:User {id: $target})
+		MATCH p = %s((a)%s(b))
+	`, shortest, pattern)
+	if !q.ViaGroups {
+		query += "WHERE all(n IN nodes(p) WHERE n:User)"
+	}
+	query += `
+		RETURN p
+		LIMIT $limit
+	`
+
+	return query, nil
+}
+
+// relationshipPattern Шаблон связи вида -[:Follow|Subscribe*..3]-> с направлением обхода.
+// Типы связей нельзя передать параметром, поэтому допускаются только Follow и Subscribe.
+func relationshipPattern(types []string, direction, length string) (string, error) {
+	if len(types) == 0 {
+		return "", fmt.Errorf("no relationship types")
+	}
+
+	for _, relType := range types {
+		if relType != models.RelationshipFollow && relType != models.RelationshipSubscribe {
+			return "", fmt.Errorf("unsupported relationship type %q", relType)
+		}
+	}
+
+	var left, right string
+	switch direction {
+	case models.DirectionOut:
+		left, right = "-", "->"
+	case models.DirectionIn:
+		left, right = "<-", "-"
+	case models.DirectionBoth:
+		left, right = "-", "-"
+	default:
+		return "", fmt.Errorf("unsupported direction %q", direction)
+	}
+
+	return left + "[:" + strings.Join(types, "|") + length + "]" + right, nil
+}
+
+func processPath(p neo4j.Path) models.Path {
+	path := models.Path{
+		Length:        len(p.Relationships),
+		Nodes:         make([]models.Node, 0, len(p.Nodes)),
+		Relationships: make([]models.Relationship, 0, len(p.Relationships)),
+	}
+
+	nodes := make(map[string]models.Node, len(p.Nodes))
+	for _, n := range p.Nodes {
+		node := processNode(n)
+		nodes[n.ElementId] = node
+		path.Nodes = append(path.Nodes, node)
+	}
+
+	for _, rel := range p.Relationships {
+		source, target := nodes[rel.StartElementId], nodes[rel.EndElementId]
+		path.Relationships = append(path.Relationships, models.Relationship{
+			Type:        rel.Type,
+			SourceID:    source.ID,
+			TargetID:    target.ID,
+			TargetLabel: target.Label,
+		})
+	}
+
+	return path
+}
+
+// processNode Узел со всеми свойствами.
+func processNode(n neo4j.Node) models.Node {
+	node := models.Node{ElementID: n.ElementId, Properties: n.Props}
+	if len(n.Labels) > 0 {
+		node.Label = n.Labels[0]
+	}
+	if id, ok := n.Props["id"].(int64); ok {
+		node.ID = uint64(id)
+	}
+
+	return node
+}
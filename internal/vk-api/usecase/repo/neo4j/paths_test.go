@@ -0,0 +1,49 @@
+package neo4j
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPathsQuery(t *testing.T) {
+	query, err := pathsQuery(models.PathQuery{
+		Types:     []string{models.RelationshipFollow, models.RelationshipSubscribe},
+		Direction: models.DirectionIn,
+		MaxHops:   4,
+		All:       true,
+	})
+	require.NoError(t, err)
+	require.Contains(t, query, "allShortestPaths((a)<-[:Follow|Subscribe*..4]-(b))")
+	require.Contains(t, query, "WHERE all(n IN nodes(p) WHERE n:User)")
+
+	query, err = pathsQuery(models.PathQuery{
+		Types:     []string{models.RelationshipSubscribe},
+		Direction: models.DirectionBoth,
+		ViaGroups: true,
+		MaxHops:   2,
+	})
+	require.NoError(t, err)
+	require.Contains(t, query, "shortestPath((a)-[:Subscribe*..2]-(b))")
+	require.NotContains(t, query, "allShortestPaths")
+	require.NotContains(t, query, "n:User")
+
+	_, err = pathsQuery(models.PathQuery{Types: []string{"Follow]->() DETACH DELETE (a"}, Direction: models.DirectionOut, MaxHops: 1})
+	require.Error(t, err)
+
+	_, err = pathsQuery(models.PathQuery{Types: []string{models.RelationshipFollow}, Direction: "up", MaxHops: 1})
+	require.Error(t, err)
+}
+
+func TestRelationshipPattern(t *testing.T) {
+	pattern, err := relationshipPattern([]string{models.RelationshipFollow}, models.DirectionOut, "")
+	require.NoError(t, err)
+	require.Equal(t, "-[:Follow]->", pattern)
+
+	pattern, err = relationshipPattern([]string{models.RelationshipFollow, models.RelationshipSubscribe}, models.DirectionBoth, "*..3")
+	require.NoError(t, err)
+	require.Equal(t, "-[:Follow|Subscribe*..3]-", pattern)
+
+	_, err = relationshipPattern(nil, models.DirectionOut, "")
+	require.Error(t, err)
+}
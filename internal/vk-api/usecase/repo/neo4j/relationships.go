@@ -0,0 +1,85 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"slices"
+)
+
+// GetRelationships Связи Follow и Subscribe по фильтру, упорядоченные по типу, источнику, метке и id назначения.
+func (r *UserNeo4jRepo) GetRelationships(ctx context.Context, filter models.RelationshipFilter) ([]models.Relationship, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (s:User)-[r:Follow|Subscribe]->(t)
+		WHERE ($type IS NULL OR type(r) = $type)
+			AND ($source IS NULL OR s.id = $source)
+			AND ($target IS NULL OR t.id = $target)
+			AND ($targetLabel IS NULL OR $targetLabel IN labels(t))
+		RETURN type(r) AS type, s.id AS source_id, t.id AS target_id, labels(t)[0] AS target_label
+		ORDER BY type, source_id, target_label, target_id
+	`
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"type":        nullable(filter.Type),
+		"source":      nullable(filter.SourceID),
+		"target":      nullable(filter.TargetID),
+		"targetLabel": nullable(filter.TargetLabel),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := make([]models.Relationship, 0)
+	for result.Next(ctx) {
+		relationships = append(relationships, recordRelationship(result.Record()))
+	}
+
+	return relationships, result.Err()
+}
+
+// recordRelationship Связь из столбцов type, source_id, target_id и target_label.
+func recordRelationship(record *neo4j.Record) models.Relationship {
+	relType, _ := record.Get("type")
+	sourceID, _ := record.Get("source_id")
+	targetID, _ := record.Get("target_id")
+	targetLabel, _ := record.Get("target_label")
+
+	rel := models.Relationship{}
+	rel.Type, _ = relType.(string)
+	rel.TargetLabel, _ = targetLabel.(string)
+	if id, ok := sourceID.(int64); ok {
+		rel.SourceID = uint64(id)
+	}
+	if id, ok := targetID.(int64); ok {
+		rel.TargetID = uint64(id)
+	}
+
+	return rel
+}
+
+// nullable Пустое значение фильтра передается в запрос как null.
+func nullable[T comparable](v T) interface{} {
+	var zero T
+	if v == zero {
+		return nil
+	}
+
+	return v
+}
+
+// DeleteRelationship Удаляет одну связь. false - если связи нет.
+func (r *UserNeo4jRepo) DeleteRelationship(ctx context.Context, rel models.Relationship) (bool, error) {
+	if !slices.Contains(relationshipKinds, relationshipKind{rel.Type, rel.TargetLabel}) {
+		return false, fmt.Errorf("unsupported relationship (:User)-[:%s]->(:%s)", rel.Type, rel.TargetLabel)
+	}
+
+	query := fmt.Sprintf(
+		"MATCH (:User {id: $source})-[r:%s]->(:%s {id: $target}) DELETE r RETURN count(r) AS count",
+		rel.Type, rel.TargetLabel,
+	)
+
+	return r.writeCount(ctx, query, relationshipRow(rel))
+}
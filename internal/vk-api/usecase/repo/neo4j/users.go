@@ -0,0 +1,204 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// GetUsers Все пользователи без связей в порядке VK id.
+func (r *UserNeo4jRepo) GetUsers(ctx context.Context) ([]models.User, error) {
+	return r.queryUsers(ctx, "MATCH (n:User) RETURN n ORDER BY n.id", nil)
+}
+
+// GetGroups Все группы в порядке VK id.
+func (r *UserNeo4jRepo) GetGroups(ctx context.Context) ([]models.Group, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "MATCH (n:Group) RETURN n ORDER BY n.id", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]models.Group, 0)
+	for result.Next(ctx) {
+		n, err := recordNode(result.Record(), "n")
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, processGroupNode(n))
+	}
+
+	return groups, result.Err()
+}
+
+// FindUser Пользователь без связей, nil - если его нет.
+func (r *UserNeo4jRepo) FindUser(ctx context.Context, id uint64) (*models.User, error) {
+	users, err := r.queryUsers(ctx, "MATCH (n:User {id: $id}) RETURN n", map[string]interface{}{"id": id})
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+
+	return &users[0], nil
+}
+
+// FindGroup Группа без подписчиков, nil - если ее нет.
+func (r *UserNeo4jRepo) FindGroup(ctx context.Context, id uint64) (*models.Group, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "MATCH (n:Group {id: $id}) RETURN n", map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Next(ctx) {
+		return nil, result.Err()
+	}
+
+	n, err := recordNode(result.Record(), "n")
+	if err != nil {
+		return nil, err
+	}
+
+	group := processGroupNode(n)
+	return &group, nil
+}
+
+// UpdateUser Перезаписывает свойства существующего пользователя. false - если пользователя нет.
+func (r *UserNeo4jRepo) UpdateUser(ctx context.Context, user models.User) (bool, error) {
+	return r.writeCount(ctx,
+		"MATCH (u:User {id: $id}) "+
+			"SET u.screen_name = $screen_name, u.name = $name, u.sex = $sex, u.city = $city "+
+			"RETURN count(u) AS count",
+		userRow(user),
+	)
+}
+
+// UpdateGroup Перезаписывает свойства существующей группы. false - если группы нет.
+func (r *UserNeo4jRepo) UpdateGroup(ctx context.Context, group models.Group) (bool, error) {
+	return r.writeCount(ctx,
+		"MATCH (g:Group {id: $id}) "+
+			"SET g.name = $name, g.screen_name = $screen_name "+
+			"RETURN count(g) AS count",
+		groupRow(group),
+	)
+}
+
+// writeCount Выполняет запрос записи со столбцом count. true - если запрос затронул хотя бы один элемент.
+func (r *UserNeo4jRepo) writeCount(ctx context.Context, query string, params map[string]interface{}) (bool, error) {
+	session := r.writeSession(ctx)
+	defer session.Close(ctx)
+
+	count, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		count, _ := record.Get("count")
+		return count, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	n, ok := count.(int64)
+	if !ok {
+		return false, fmt.Errorf("cant assert count %+v (type %T) to int64", count, count)
+	}
+
+	return n > 0, nil
+}
+
+// GetFollowers Подписчики пользователя: (f:User)-[:Follow]->(u).
+func (r *UserNeo4jRepo) GetFollowers(ctx context.Context, userID uint64) ([]models.User, error) {
+	return r.queryUsers(ctx,
+		"MATCH (n:User)-[:Follow]->(:User {id: $id}) RETURN n ORDER BY n.id",
+		map[string]interface{}{"id": userID},
+	)
+}
+
+// GetSubscribers Подписчики группы: (u:User)-[:Subscribe]->(g).
+func (r *UserNeo4jRepo) GetSubscribers(ctx context.Context, groupID uint64) ([]models.User, error) {
+	return r.queryUsers(ctx,
+		"MATCH (n:User)-[:Subscribe]->(:Group {id: $id}) RETURN n ORDER BY n.id",
+		map[string]interface{}{"id": groupID},
+	)
+}
+
+// GetSubscriptions Пользователи и группы, на которые подписан пользователь.
+func (r *UserNeo4jRepo) GetSubscriptions(ctx context.Context, userID uint64) (models.Subscriptions, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx,
+		"MATCH (:User {id: $id})-[:Subscribe]->(n) RETURN n ORDER BY n.id",
+		map[string]interface{}{"id": userID},
+	)
+	if err != nil {
+		return models.Subscriptions{}, err
+	}
+
+	subscriptions := models.Subscriptions{Users: make([]models.User, 0), Groups: make([]models.Group, 0)}
+	for result.Next(ctx) {
+		n, err := recordNode(result.Record(), "n")
+		if err != nil {
+			return models.Subscriptions{}, err
+		}
+
+		switch n.Labels[0] {
+		case models.LabelUser:
+			subscriptions.Users = append(subscriptions.Users, processUserNode(n))
+		case models.LabelGroup:
+			subscriptions.Groups = append(subscriptions.Groups, processGroupNode(n))
+		}
+	}
+
+	return subscriptions, result.Err()
+}
+
+// queryUsers Пользователи из столбца n.
+func (r *UserNeo4jRepo) queryUsers(ctx context.Context, query string, params map[string]interface{}) ([]models.User, error) {
+	session := r.readSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.User, 0)
+	for result.Next(ctx) {
+		n, err := recordNode(result.Record(), "n")
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, processUserNode(n))
+	}
+
+	return users, result.Err()
+}
+
+func recordNode(record *neo4j.Record, key string) (neo4j.Node, error) {
+	value, _ := record.Get(key)
+	n, ok := value.(neo4j.Node)
+	if !ok {
+		return neo4j.Node{}, fmt.Errorf("cant assert node %+v (type %T) to neo4j.Node", value, value)
+	}
+
+	if len(n.Labels) == 0 {
+		return neo4j.Node{}, fmt.Errorf("can not find labels for node %+v", value)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrGroupNotFound = errors.New("group not found")
+	ErrInvalidID     = errors.New("invalid vk id")
+)
+
+func (uc *UserUsecase) ListUsers(ctx context.Context) ([]models.User, error) {
+	return uc.repo.GetUsers(ctx)
+}
+
+// FindUser Пользователь из графа без связей.
+func (uc *UserUsecase) FindUser(ctx context.Context, id uint64) (models.User, error) {
+	user, err := uc.repo.FindUser(ctx, id)
+	if err != nil {
+		return models.User{}, fmt.Errorf("uc.repo.FindUser: %w", err)
+	}
+
+	if user == nil {
+		return models.User{}, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+
+	return *user, nil
+}
+
+// CreateUser Создает или обновляет пользователя вместе с вложенными подписчиками и подписками.
+func (uc *UserUsecase) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	if user.ID == 0 {
+		return models.User{}, fmt.Errorf("%w: user id is required", ErrInvalidID)
+	}
+
+	if err := uc.SaveUser(ctx, user); err != nil {
+		return models.User{}, err
+	}
+
+	return uc.FindUser(ctx, user.ID)
+}
+
+// UpdateUser Перезаписывает свойства существующего пользователя, связи не меняются.
+func (uc *UserUsecase) UpdateUser(ctx context.Context, user models.User) (models.User, error) {
+	ok, err := uc.repo.UpdateUser(ctx, user)
+	if err != nil {
+		return models.User{}, fmt.Errorf("uc.repo.UpdateUser: %w", err)
+	}
+
+	if !ok {
+		return models.User{}, fmt.Errorf("%w: %d", ErrUserNotFound, user.ID)
+	}
+
+	return uc.FindUser(ctx, user.ID)
+}
+
+// DeleteUser Удаляет пользователя вместе со всеми его связями.
+func (uc *UserUsecase) DeleteUser(ctx context.Context, id uint64) error {
+	if _, err := uc.FindUser(ctx, id); err != nil {
+		return err
+	}
+
+	return uc.repo.DeleteNode(ctx, models.NodeRef{Label: models.LabelUser, ID: id})
+}
+
+func (uc *UserUsecase) ListFollowers(ctx context.Context, userID uint64) ([]models.User, error) {
+	if _, err := uc.FindUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetFollowers(ctx, userID)
+}
+
+func (uc *UserUsecase) ListSubscriptions(ctx context.Context, userID uint64) (models.Subscriptions, error) {
+	if _, err := uc.FindUser(ctx, userID); err != nil {
+		return models.Subscriptions{}, err
+	}
+
+	return uc.repo.GetSubscriptions(ctx, userID)
+}
+
+func (uc *UserUsecase) ListGroups(ctx context.Context) ([]models.Group, error) {
+	return uc.repo.GetGroups(ctx)
+}
+
+// FindGroup Группа из графа без подписчиков.
+func (uc *UserUsecase) FindGroup(ctx context.Context, id uint64) (models.Group, error) {
+	group, err := uc.repo.FindGroup(ctx, id)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("uc.repo.FindGroup: %w", err)
+	}
+
+	if group == nil {
+		return models.Group{}, fmt.Errorf("%w: %d", ErrGroupNotFound, id)
+	}
+
+	return *group, nil
+}
+
+// CreateGroup Создает или обновляет группу и подписки на нее уже существующих пользователей.
+func (uc *UserUsecase) CreateGroup(ctx context.Context, group models.GroupWithSubscribers) (models.Group, error) {
+	if group.ID == 0 {
+		return models.Group{}, fmt.Errorf("%w: group id is required", ErrInvalidID)
+	}
+
+	if err := uc.SaveGroup(ctx, group); err != nil {
+		return models.Group{}, err
+	}
+
+	return uc.FindGroup(ctx, group.ID)
+}
+
+// UpdateGroup Перезаписывает свойства существующей группы, подписки не меняются.
+func (uc *UserUsecase) UpdateGroup(ctx context.Context, group models.Group) (models.Group, error) {
+	ok, err := uc.repo.UpdateGroup(ctx, group)
+	if err != nil {
+		return models.Group{}, fmt.Errorf("uc.repo.UpdateGroup: %w", err)
+	}
+
+	if !ok {
+		return models.Group{}, fmt.Errorf("%w: %d", ErrGroupNotFound, group.ID)
+	}
+
+	return uc.FindGroup(ctx, group.ID)
+}
+
+// DeleteGroup Удаляет группу вместе с подписками на нее.
+func (uc *UserUsecase) DeleteGroup(ctx context.Context, id uint64) error {
+	if _, err := uc.FindGroup(ctx, id); err != nil {
+		return err
+	}
+
+	return uc.repo.DeleteNode(ctx, models.NodeRef{Label: models.LabelGroup, ID: id})
+}
+
+func (uc *UserUsecase) ListSubscribers(ctx context.Context, groupID uint64) ([]models.User, error) {
+	if _, err := uc.FindGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetSubscribers(ctx, groupID)
+}
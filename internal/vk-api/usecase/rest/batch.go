@@ -0,0 +1,314 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/bytedance/sonic"
+	"slices"
+	"strings"
+)
+
+const (
+	executeMethodName = "method/execute"
+
+	// MaxBatchSize Ограничение VK на число вызовов API внутри одного execute.
+	MaxBatchSize = 25
+
+	// usersPerBatch Один users.get на всю пачку и по два списочных вызова на пользователя.
+	usersPerBatch = (MaxBatchSize - 1) / 2
+)
+
+var (
+	ErrBatchNotExecuted = errors.New("batch call has not been executed")
+	ErrBatchTooLarge    = fmt.Errorf("batch call count exceeds %d", MaxBatchSize)
+)
+
+type batchCall interface {
+	code() (string, error)
+	resolve(raw []byte, err error)
+}
+
+// Call Отложенный результат вызова, добавленного в Batch. Доступен после Batch.Execute.
+type Call[T any] struct {
+	method string
+	params map[string]string
+	decode func([]byte) (T, error)
+
+	done   bool
+	result T
+	err    error
+}
+
+// Result Результат вызова или ошибка VK API, относящаяся именно к этому вызову.
+func (c *Call[T]) Result() (T, error) {
+	if !c.done {
+		var zero T
+		return zero, ErrBatchNotExecuted
+	}
+
+	return c.result, c.err
+}
+
+// code Вызов на VKScript. Ключи аргументов сортируются, чтобы код был детерминированным.
+func (c *Call[T]) code() (string, error) {
+	keys := make([]string, 0, len(c.params))
+	for key := range c.params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var builder strings.Builder
+	builder.WriteString("API." + c.method + "({")
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+
+		k, err := sonic.Marshal(key)
+		if err != nil {
+			return "", err
+		}
+
+		v, err := sonic.Marshal(c.params[key])
+		if err != nil {
+			return "", err
+		}
+
+		builder.Write(k)
+		builder.WriteString(":")
+		builder.Write(v)
+	}
+	builder.WriteString("})")
+
+	return builder.String(), nil
+}
+
+func (c *Call[T]) resolve(raw []byte, err error) {
+	c.done = true
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	c.result, c.err = c.decode(raw)
+}
+
+func decodeJSON[T any](raw []byte) (T, error) {
+	var v T
+	err := sonic.Unmarshal(raw, &v)
+	return v, err
+}
+
+// Batch Набор вызовов VK API, выполняемых через метод execute пачками по MaxBatchSize.
+type Batch struct {
+	client *VKClient
+	calls  []batchCall
+}
+
+func (c *VKClient) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+func addCall[T any](b *Batch, method string, params map[string]string, decode func([]byte) (T, error)) *Call[T] {
+	call := &Call[T]{
+		method: method,
+		params: params,
+		decode: decode,
+	}
+	b.calls = append(b.calls, call)
+
+	return call
+}
+
+func (b *Batch) GetUsers(ids ...uint64) *Call[[]models.User] {
+	params, _ := b.client.usersGetParams(ids...)
+	return addCall(b, "users.get", params, decodeJSON[[]models.User])
+}
+
+// GetFollowers Одна страница подписчиков, начиная с offset.
+func (b *Batch) GetFollowers(id uint64, offset int) *Call[Page[models.User]] {
+	params := followersParams(id)
+	params["offset"] = fmt.Sprint(offset)
+	params["count"] = fmt.Sprint(b.client.pageCount(maxFollowersPageSize, offset))
+
+	return addCall(b, "users.getFollowers", params, decodeJSON[Page[models.User]])
+}
+
+// SubscriptionsPage Страница подписок пользователя.
+type SubscriptionsPage struct {
+	Count         uint64
+	Subscriptions models.Subscriptions
+
+	items []userGroup
+}
+
+// GetSubscriptions Одна страница подписок, начиная с offset.
+func (b *Batch) GetSubscriptions(id uint64, offset int) *Call[SubscriptionsPage] {
+	params := subscriptionsParams(id)
+	params["offset"] = fmt.Sprint(offset)
+	params["count"] = fmt.Sprint(b.client.pageCount(maxSubscriptionsPageSize, offset))
+
+	return addCall(b, "users.getSubscriptions", params, func(raw []byte) (SubscriptionsPage, error) {
+		p, err := decodeJSON[Page[userGroup]](raw)
+		if err != nil {
+			return SubscriptionsPage{}, err
+		}
+
+		return SubscriptionsPage{Count: p.Count, Subscriptions: splitSubscriptions(p.Items), items: p.Items}, nil
+	})
+}
+
+// Execute Выполняет все добавленные вызовы. Ошибки отдельных вызовов доступны через Call.Result,
+// возвращается только ошибка запроса execute целиком.
+func (b *Batch) Execute(ctx context.Context) error {
+	for chunk := range slices.Chunk(b.calls, MaxBatchSize) {
+		if err := b.client.execute(ctx, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *VKClient) execute(ctx context.Context, calls []batchCall) error {
+	if len(calls) > MaxBatchSize {
+		return ErrBatchTooLarge
+	}
+
+	codes := make([]string, 0, len(calls))
+	for _, call := range calls {
+		code, err := call.code()
+		if err != nil {
+			return err
+		}
+		codes = append(codes, code)
+	}
+
+	response, err := callEnvelope[[]json.RawMessage](ctx, c, executeMethodName, map[string]string{
+		"code": "return [" + strings.Join(codes, ",") + "];",
+	})
+	if err != nil {
+		for _, call := range calls {
+			call.resolve(nil, err)
+		}
+
+		return err
+	}
+
+	// Неудавшиеся вызовы возвращают false, их ошибки идут в execute_errors в том же порядке.
+	executeErrors := response.ExecuteErrors
+	for i, call := range calls {
+		if i >= len(response.Response) {
+			call.resolve(nil, fmt.Errorf("execute: no result for call %d", i))
+			continue
+		}
+
+		raw := bytes.TrimSpace(response.Response[i])
+		if !bytes.Equal(raw, []byte("false")) {
+			call.resolve(raw, nil)
+			continue
+		}
+
+		if len(executeErrors) == 0 {
+			call.resolve(nil, &VKError{Code: ErrCodeExecuteCodeFailed, Message: "call failed without execute_errors entry"})
+			continue
+		}
+
+		vkErr := executeErrors[0]
+		executeErrors = executeErrors[1:]
+		call.resolve(nil, &vkErr)
+	}
+
+	return nil
+}
+
+// UserConnections Пользователь вместе с подписчиками и подписками.
+// Ошибки получения списков (например, закрытый профиль) не прерывают обработку остальных пользователей.
+type UserConnections struct {
+	User               models.User
+	FollowersCount     uint64
+	SubscriptionsCount uint64
+	FollowersErr       error
+	SubscriptionsErr   error
+}
+
+// GetUsersWithConnections Получает пользователей с подписчиками и подписками, упаковывая вызовы в execute
+// по usersPerBatch пользователей. Остальные страницы длинных списков догружаются обычными запросами.
+// Пользователи, которых VK не вернул, пропускаются.
+func (c *VKClient) GetUsersWithConnections(ctx context.Context, ids ...uint64) ([]UserConnections, error) {
+	result := make([]UserConnections, 0, len(ids))
+
+	for chunk := range slices.Chunk(ids, usersPerBatch) {
+		b := c.NewBatch()
+		usersCall := b.GetUsers(chunk...)
+
+		followersCalls := make([]*Call[Page[models.User]], len(chunk))
+		subscriptionsCalls := make([]*Call[SubscriptionsPage], len(chunk))
+		for i, id := range chunk {
+			followersCalls[i] = b.GetFollowers(id, 0)
+			subscriptionsCalls[i] = b.GetSubscriptions(id, 0)
+		}
+
+		if err := b.Execute(ctx); err != nil {
+			return result, err
+		}
+
+		users, err := usersCall.Result()
+		if err != nil {
+			return result, err
+		}
+
+		usersByID := make(map[uint64]models.User, len(users))
+		for _, user := range users {
+			usersByID[user.ID] = user
+		}
+
+		for i, id := range chunk {
+			user, ok := usersByID[id]
+			if !ok {
+				continue
+			}
+
+			conn := UserConnections{User: user}
+			conn.User.Followers, conn.FollowersCount, conn.FollowersErr = c.restFollowers(ctx, id, followersCalls[i])
+			conn.User.Subscriptions, conn.SubscriptionsCount, conn.SubscriptionsErr = c.restSubscriptions(ctx, id, subscriptionsCalls[i])
+
+			result = append(result, conn)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *VKClient) restFollowers(ctx context.Context, id uint64, call *Call[Page[models.User]]) ([]models.User, uint64, error) {
+	p, err := call.Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return paginateFrom(ctx, c, getFollowersMethodName, followersParams(id), maxFollowersPageSize,
+		c.pageCount(maxFollowersPageSize, 0), p.Items, p.Count)
+}
+
+func (c *VKClient) restSubscriptions(ctx context.Context, id uint64, call *Call[SubscriptionsPage]) (models.Subscriptions, uint64, error) {
+	p, err := call.Result()
+	if err != nil {
+		return models.Subscriptions{}, 0, err
+	}
+
+	items, total, err := paginateFrom(ctx, c, getSubscriptionsMethodName, subscriptionsParams(id), maxSubscriptionsPageSize,
+		c.pageCount(maxSubscriptionsPageSize, 0), p.items, p.Count)
+	if err != nil {
+		return models.Subscriptions{}, 0, err
+	}
+
+	return splitSubscriptions(items), total, nil
+}
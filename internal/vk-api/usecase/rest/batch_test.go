@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchExecuteDemultiplexing(t *testing.T) {
+	var codes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/method/execute"))
+		codes = append(codes, r.FormValue("code"))
+
+		fmt.Fprint(w, `{"response":[[{"id":1,"first_name":"Pavel"}],{"count":2,"items":[{"id":2},{"id":3}]},false],`+
+			`"execute_errors":[{"method":"users.getSubscriptions","error_code":30,"error_msg":"This profile is private"}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithRateLimit(0, 0))
+
+	b := c.NewBatch()
+	users := b.GetUsers(1)
+	followers := b.GetFollowers(1, 0)
+	subscriptions := b.GetSubscriptions(1, 0)
+
+	_, err := users.Result()
+	require.ErrorIs(t, err, ErrBatchNotExecuted)
+
+	require.NoError(t, b.Execute(context.Background()))
+	require.Len(t, codes, 1)
+	require.Contains(t, codes[0], `API.users.get({"fields":"screen_name,sex,city","user_ids":"1"})`)
+
+	u, err := users.Result()
+	require.NoError(t, err)
+	require.Equal(t, "Pavel", u[0].FirstName)
+
+	f, err := followers.Result()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), f.Count)
+	require.Len(t, f.Items, 2)
+
+	_, err = subscriptions.Result()
+	require.True(t, IsAccessDenied(err))
+
+	vkErr, _ := AsVKError(err)
+	require.Equal(t, "users.getSubscriptions", vkErr.Method)
+}
+
+func TestBatchExecuteChunks(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		calls := strings.Count(r.FormValue("code"), "API.")
+
+		results := make([]string, calls)
+		for i := range results {
+			results[i] = `[]`
+		}
+		fmt.Fprintf(w, `{"response":[%s]}`, strings.Join(results, ","))
+	}))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithRateLimit(0, 0))
+
+	b := c.NewBatch()
+	for i := 0; i < MaxBatchSize+1; i++ {
+		b.GetUsers(uint64(i + 1))
+	}
+
+	require.NoError(t, b.Execute(context.Background()))
+	require.Equal(t, 2, requests)
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Коды ошибок VK API, https://dev.vk.com/ru/reference/errors
+const (
+	ErrCodeUnknown           = 1
+	ErrCodeInvalidToken      = 5
+	ErrCodeTooManyRequests   = 6
+	ErrCodeFloodControl      = 9
+	ErrCodeInternalServer    = 10
+	ErrCodeExecuteCodeFailed = 13
+	ErrCodeCaptchaNeeded     = 14
+	ErrCodeAccessDenied      = 15
+	ErrCodeUserDeleted       = 18
+	ErrCodeRateLimitReached  = 29
+	ErrCodePrivateProfile    = 30
+	ErrCodeInvalidUserID     = 113
+)
+
+type RequestParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// VKError Ошибка, возвращенная VK API в поле error ответа.
+type VKError struct {
+	Code          int            `json:"error_code"`
+	Message       string         `json:"error_msg"`
+	RequestParams []RequestParam `json:"request_params"`
+	CaptchaSID    string         `json:"captcha_sid,omitempty"`
+	CaptchaImg    string         `json:"captcha_img,omitempty"`
+	// Method Метод, вызов которого завершился ошибкой (заполняется для вызовов внутри execute).
+	Method string `json:"method,omitempty"`
+}
+
+func (e *VKError) Error() string {
+	return fmt.Sprintf("vk api error %d: %s", e.Code, e.Message)
+}
+
+func (e *VKError) IsRateLimit() bool {
+	return e.Code == ErrCodeTooManyRequests || e.Code == ErrCodeFloodControl || e.Code == ErrCodeRateLimitReached
+}
+
+func (e *VKError) IsAccessDenied() bool {
+	return e.Code == ErrCodeAccessDenied || e.Code == ErrCodePrivateProfile
+}
+
+func (e *VKError) IsUserDeleted() bool {
+	return e.Code == ErrCodeUserDeleted
+}
+
+func (e *VKError) IsInvalidToken() bool {
+	return e.Code == ErrCodeInvalidToken
+}
+
+func (e *VKError) IsCaptcha() bool {
+	return e.Code == ErrCodeCaptchaNeeded
+}
+
+// Temporary Ошибки, после которых имеет смысл повторить запрос через небольшую паузу.
+// Используется логикой повторов из pkg/rest.
+func (e *VKError) Temporary() bool {
+	return e.Code == ErrCodeTooManyRequests || e.Code == ErrCodeUnknown || e.Code == ErrCodeInternalServer
+}
+
+// APIError Отличает ошибку ответа VK API от сетевых ошибок в логике повторов из pkg/rest.
+func (e *VKError) APIError() {}
+
+// AsVKError Извлекает VKError из цепочки ошибок.
+func AsVKError(err error) (*VKError, bool) {
+	var vkErr *VKError
+	if errors.As(err, &vkErr) {
+		return vkErr, true
+	}
+
+	return nil, false
+}
+
+func IsRateLimit(err error) bool {
+	vkErr, ok := AsVKError(err)
+	return ok && vkErr.IsRateLimit()
+}
+
+func IsAccessDenied(err error) bool {
+	vkErr, ok := AsVKError(err)
+	return ok && vkErr.IsAccessDenied()
+}
+
+func IsUserDeleted(err error) bool {
+	vkErr, ok := AsVKError(err)
+	return ok && vkErr.IsUserDeleted()
+}
+
+func IsInvalidToken(err error) bool {
+	vkErr, ok := AsVKError(err)
+	return ok && vkErr.IsInvalidToken()
+}
+
+func IsCaptcha(err error) bool {
+	vkErr, ok := AsVKError(err)
+	return ok && vkErr.IsCaptcha()
+}
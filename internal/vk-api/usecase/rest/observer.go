@@ -0,0 +1,23 @@
+package rest
+
+import (
+	"context"
+	"time"
+)
+
+// WaitObserver Получает сведения об ожидании лимита запросов перед вызовом метода.
+type WaitObserver func(method string, waited time.Duration)
+
+type waitObserverKey struct{}
+
+// WithWaitObserver Возвращает контекст, для запросов в котором клиент сообщает observer о каждом
+// ожидании лимита запросов. Позволяет отнести ожидания к конкретной задаче обхода.
+func WithWaitObserver(ctx context.Context, observer WaitObserver) context.Context {
+	return context.WithValue(ctx, waitObserverKey{}, observer)
+}
+
+func observeWait(ctx context.Context, method string, waited time.Duration) {
+	if observer, ok := ctx.Value(waitObserverKey{}).(WaitObserver); ok && observer != nil {
+		observer(method, waited)
+	}
+}
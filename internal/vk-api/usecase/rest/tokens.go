@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"github.com/Nimartemoff/vk-api/pkg/ratelimit"
+	"sync"
+	"time"
+)
+
+const DefaultQuarantine = 10 * time.Minute
+
+var ErrNoTokens = errors.New("vk token pool is empty")
+
+type token struct {
+	value   string
+	limiter *ratelimit.Limiter
+
+	quarantinedUntil time.Time
+	requests         uint64
+	failures         uint64
+	lastError        string
+}
+
+// TokenStatus Состояние токена в пуле. Сам токен маскируется.
+type TokenStatus struct {
+	Token            string     `json:"token"`
+	Available        bool       `json:"available"`
+	QuarantinedUntil *time.Time `json:"quarantined_until,omitempty"`
+	Requests         uint64     `json:"requests"`
+	Failures         uint64     `json:"failures"`
+	LastError        string     `json:"last_error,omitempty"`
+	RPS              float64    `json:"rps"`
+}
+
+// TokenPool Пул токенов VK: запросы распределяются по кругу, у каждого токена свой лимит частоты.
+// Токен, получивший ошибку 5 или 29, временно исключается из ротации.
+type TokenPool struct {
+	mu         sync.Mutex
+	tokens     []*token
+	next       int
+	quarantine time.Duration
+}
+
+func NewTokenPool(tokens []string, rps float64, burst int, quarantine time.Duration) *TokenPool {
+	p := &TokenPool{quarantine: quarantine}
+
+	for _, value := range tokens {
+		if value == "" {
+			continue
+		}
+
+		p.tokens = append(p.tokens, &token{
+			value:   value,
+			limiter: ratelimit.New(rps, burst),
+		})
+	}
+
+	return p
+}
+
+// acquire Выбирает следующий доступный токен и дожидается его лимита.
+// Если все токены на карантине, ждет освобождения ближайшего.
+func (p *TokenPool) acquire(ctx context.Context) (*token, time.Duration, error) {
+	var waited time.Duration
+
+	for {
+		t, release, err := p.pick(time.Now())
+		if err != nil {
+			return nil, waited, err
+		}
+
+		if t != nil {
+			w, err := t.limiter.Wait(ctx)
+			return t, waited + w, err
+		}
+
+		timer := time.NewTimer(release)
+		select {
+		case <-timer.C:
+			waited += release
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waited, ctx.Err()
+		}
+	}
+}
+
+// pick Возвращает следующий токен не на карантине либо время до освобождения ближайшего.
+func (p *TokenPool) pick(now time.Time) (*token, time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.tokens) == 0 {
+		return nil, 0, ErrNoTokens
+	}
+
+	var release time.Duration
+	for i := 0; i < len(p.tokens); i++ {
+		t := p.tokens[(p.next+i)%len(p.tokens)]
+		if wait := t.quarantinedUntil.Sub(now); wait > 0 {
+			if release == 0 || wait < release {
+				release = wait
+			}
+			continue
+		}
+
+		p.next = (p.next + i + 1) % len(p.tokens)
+		t.requests++
+		return t, 0, nil
+	}
+
+	return nil, release, nil
+}
+
+// report Учитывает результат запроса. Возвращает true, если токен отправлен на карантин
+// и в пуле остались другие доступные токены.
+func (p *TokenPool) report(t *token, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t.failures++
+	t.lastError = err.Error()
+
+	vkErr, ok := AsVKError(err)
+	if !ok || !(vkErr.IsInvalidToken() || vkErr.Code == ErrCodeRateLimitReached) {
+		return false
+	}
+
+	now := time.Now()
+	t.quarantinedUntil = now.Add(p.quarantine)
+
+	for _, other := range p.tokens {
+		if other != t && !other.quarantinedUntil.After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *TokenPool) Status() []TokenStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]TokenStatus, 0, len(p.tokens))
+	for _, t := range p.tokens {
+		status := TokenStatus{
+			Token:     maskToken(t.value),
+			Available: !t.quarantinedUntil.After(now),
+			Requests:  t.requests,
+			Failures:  t.failures,
+			LastError: t.lastError,
+			RPS:       t.limiter.Rate(),
+		}
+
+		if !status.Available {
+			until := t.quarantinedUntil
+			status.QuarantinedUntil = &until
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+func maskToken(value string) string {
+	const visible = 4
+	if len(value) <= visible*2 {
+		return "****"
+	}
+
+	return value[:visible] + "****" + value[len(value)-visible:]
+}
+
+// tokenRotateError Ошибка токена, после которой запрос можно повторить с другим токеном пула.
+type tokenRotateError struct {
+	*VKError
+}
+
+func (e tokenRotateError) Temporary() bool {
+	return true
+}
+
+func (e tokenRotateError) Unwrap() error {
+	return e.VKError
+}
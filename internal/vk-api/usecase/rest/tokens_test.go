@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenPoolQuarantine(t *testing.T) {
+	var used []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, token)
+
+		if token == "bad-token-value" {
+			fmt.Fprint(w, `{"error":{"error_code":5,"error_msg":"User authorization failed: invalid access_token"}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"response":[{"id":1}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"bad-token-value", "good-token-value"},
+		WithRateLimit(0, 0), WithQuarantine(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		users, err := c.GetUsers(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+	}
+
+	// Плохой токен использован один раз и после ошибки 5 больше не выдается.
+	require.Equal(t, []string{"bad-token-value", "good-token-value", "good-token-value", "good-token-value"}, used)
+
+	status := c.TokensStatus()
+	require.Len(t, status, 2)
+	require.False(t, status[0].Available)
+	require.NotNil(t, status[0].QuarantinedUntil)
+	require.Equal(t, "bad-****alue", status[0].Token)
+	require.True(t, status[1].Available)
+}
+
+func TestTokenPoolAllQuarantined(t *testing.T) {
+	p := NewTokenPool([]string{"token-value-1"}, 0, 0, time.Hour)
+
+	tok, _, err := p.acquire(context.Background())
+	require.NoError(t, err)
+	require.False(t, p.report(tok, &VKError{Code: ErrCodeRateLimitReached}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = p.acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -2,8 +2,10 @@ package rest
 
 import (
 	"context"
+	"fmt"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
 	"github.com/Nimartemoff/vk-api/pkg/rest"
+	"github.com/avast/retry-go"
 	"github.com/bytedance/sonic"
 	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
@@ -14,10 +16,7 @@ import (
 )
 
 const (
-	clientRetryCount       = 5
-	clientRetryWaitTime    = time.Second * 1
-	clientRetryMaxWaitTime = time.Second * 5
-	correctionTime         = 15 * time.Second
+	correctionTime = 15 * time.Second
 
 	usersGetMethodName         = "method/users.get"
 	getFollowersMethodName     = "method/users.getFollowers"
@@ -27,7 +26,15 @@ const (
 	usersGetFields         = "screen_name,sex,city"
 	getSubscriptionsFields = "name,screen_name"
 
-	count = 3
+	// Максимальные значения count, которые принимает VK API.
+	maxFollowersPageSize     = 1000
+	maxSubscriptionsPageSize = 200
+
+	DefaultPageSize = maxFollowersPageSize
+
+	// Ограничение VK API для пользовательского токена - 3 запроса в секунду.
+	DefaultRPS   = 3
+	DefaultBurst = 1
 
 	base = 10
 )
@@ -35,225 +42,357 @@ const (
 type VKClient struct {
 	baseURLs []string
 	resty    *resty.Client
+
+	pageSize int
+	maxItems int
+
+	rps        float64
+	burst      int
+	quarantine time.Duration
+	tokens     *TokenPool
+}
+
+type Option func(*VKClient)
+
+// WithPageSize Размер страницы для списочных методов (параметр count).
+func WithPageSize(pageSize int) Option {
+	return func(c *VKClient) {
+		if pageSize > 0 {
+			c.pageSize = pageSize
+		}
+	}
+}
+
+// WithMaxItems Ограничение на число элементов, получаемых списочным методом. 0 - без ограничения.
+func WithMaxItems(maxItems int) Option {
+	return func(c *VKClient) {
+		if maxItems >= 0 {
+			c.maxItems = maxItems
+		}
+	}
+}
+
+// WithRateLimit Ограничение частоты запросов к VK API на каждый токен. При rps <= 0 запросы не ограничиваются.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *VKClient) {
+		c.rps = rps
+		c.burst = burst
+	}
 }
 
-func NewVKClient(baseURLs []string, token string) *VKClient {
+// WithQuarantine Время, на которое токен исключается из ротации после ошибки 5 или 29.
+func WithQuarantine(quarantine time.Duration) Option {
+	return func(c *VKClient) {
+		if quarantine > 0 {
+			c.quarantine = quarantine
+		}
+	}
+}
+
+// NewVKClient Повторы запросов выполняет только callEnvelope: каждая попытка заново берет токен из пула
+// и ждет лимита, поэтому повторы resty не включаются.
+func NewVKClient(baseURLs []string, tokens []string, opts ...Option) *VKClient {
 	rc := resty.New()
-	rc.SetRetryCount(clientRetryCount).
-		SetRetryWaitTime(clientRetryWaitTime).
-		SetRetryMaxWaitTime(clientRetryMaxWaitTime).
-		AddRetryAfterErrorCondition().
-		SetAuthToken(token).
-		SetQueryParam("lang", "ru")
-
-	return &VKClient{
-		baseURLs: baseURLs,
-		resty:    rc,
+	rc.SetQueryParam("lang", "ru")
+
+	c := &VKClient{
+		baseURLs:   baseURLs,
+		resty:      rc,
+		pageSize:   DefaultPageSize,
+		rps:        DefaultRPS,
+		burst:      DefaultBurst,
+		quarantine: DefaultQuarantine,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.tokens = NewTokenPool(tokens, c.rps, c.burst, c.quarantine)
+
+	return c
+}
+
+// TokensStatus Состояние пула токенов.
+func (c *VKClient) TokensStatus() []TokenStatus {
+	return c.tokens.Status()
 }
 
 func (c *VKClient) GetUsers(ctx context.Context, ids ...uint64) ([]models.User, error) {
-	userIDs, err := c.parseUserIDs(ids...)
+	params, err := c.usersGetParams(ids...)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp *resty.Response
+	return call[[]models.User](ctx, c, usersGetMethodName, params)
+}
 
-	var response struct {
-		Users []models.User `json:"response"`
+// GetUsersByScreenNames Возвращает пользователей по коротким именам (users.get принимает их вместо id).
+func (c *VKClient) GetUsersByScreenNames(ctx context.Context, screenNames ...string) ([]models.User, error) {
+	if len(screenNames) == 0 {
+		return nil, nil
 	}
 
-	for _, baseURL := range c.baseURLs {
-		urlr, err := url.JoinPath(baseURL, usersGetMethodName)
-		if err != nil {
-			return nil, err
-		}
+	return call[[]models.User](ctx, c, usersGetMethodName, map[string]string{
+		"fields":   usersGetFields,
+		"user_ids": strings.Join(screenNames, ","),
+	})
+}
 
-		if err := rest.GetRestClient(func() (errGet error) {
-			req := c.resty.R().
-				SetContext(ctx).
-				SetQueryParams(map[string]string{
-					"v":      apiVersion,
-					"fields": usersGetFields,
-				})
+// GetFollowers Возвращает подписчиков пользователя и их общее число по данным VK.
+func (c *VKClient) GetFollowers(ctx context.Context, id uint64) (followers []models.User, total uint64, err error) {
+	return paginate[models.User](ctx, c, getFollowersMethodName, followersParams(id), maxFollowersPageSize)
+}
 
-			if userIDs != "" {
-				req.SetQueryParam("user_ids", userIDs)
-			}
+// GetSubscriptions Возвращает подписки пользователя (пользователи и группы) и их общее число по данным VK.
+func (c *VKClient) GetSubscriptions(ctx context.Context, id uint64) (subscriptions models.Subscriptions, total uint64, err error) {
+	items, total, err := paginate[userGroup](ctx, c, getSubscriptionsMethodName, subscriptionsParams(id), maxSubscriptionsPageSize)
+	if err != nil {
+		return subscriptions, 0, err
+	}
 
-			resp, errGet = req.Get(urlr)
+	subscriptions = splitSubscriptions(items)
 
-			if errGet != nil {
-				return errGet
-			}
+	log.Info().Msgf("Обошел подписки: %+v", subscriptions)
+	return subscriptions, total, nil
+}
 
-			return nil
-		}); err != nil {
-			log.Error().Err(err).Send()
-			continue
-		}
+func (c *VKClient) usersGetParams(ids ...uint64) (map[string]string, error) {
+	userIDs, err := c.parseUserIDs(ids...)
+	if err != nil {
+		return nil, err
+	}
 
-		body := resp.Body()
-		if body == nil {
-			log.Warn().Msg("body == nil")
-			continue
-		}
+	params := map[string]string{
+		"fields": usersGetFields,
+	}
 
-		if err := sonic.Unmarshal(body, &response); err != nil {
-			log.Error().Err(err).Send()
-			continue
-		}
+	if userIDs != "" {
+		params["user_ids"] = userIDs
+	}
+
+	return params, nil
+}
 
-		return response.Users, nil
+func followersParams(id uint64) map[string]string {
+	return map[string]string{
+		"user_id": strconv.FormatUint(id, base),
+		"fields":  usersGetFields,
 	}
+}
 
-	return nil, err
+func subscriptionsParams(id uint64) map[string]string {
+	return map[string]string{
+		"user_id":  strconv.FormatUint(id, base),
+		"extended": "1",
+	}
 }
 
-func (c *VKClient) GetFollowers(ctx context.Context, id uint64) (followers []models.User, err error) {
-	userID := strconv.FormatUint(id, 10)
-	var resp *resty.Response
+type userGroup struct {
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	Name       string      `json:"name"`
+	ScreenName string      `json:"screen_name"`
+	FirstName  string      `json:"first_name"`
+	LastName   string      `json:"last_name"`
+	Sex        byte        `json:"sex"`
+	City       models.City `json:"city"`
+}
 
-	type respParams struct {
-		Count     uint64        `json:"count"`
-		Followers []models.User `json:"items"`
+func splitSubscriptions(items []userGroup) (subscriptions models.Subscriptions) {
+	for _, userGroup := range items {
+		switch userGroup.Type {
+		case "profile":
+			subscriptions.Users = append(subscriptions.Users, models.User{
+				ID:         userGroup.ID,
+				ScreenName: userGroup.ScreenName,
+				FirstName:  userGroup.FirstName,
+				LastName:   userGroup.LastName,
+				Sex:        userGroup.Sex,
+				City:       userGroup.City,
+			})
+		case "page":
+			subscriptions.Groups = append(subscriptions.Groups, models.Group{
+				ID:         userGroup.ID,
+				Name:       userGroup.Name,
+				ScreenName: userGroup.ScreenName,
+			})
+		}
 	}
-	var response struct {
-		Params respParams `json:"response"`
+
+	return subscriptions
+}
+
+// Page Страница списочного метода VK API.
+type Page[T any] struct {
+	Count uint64 `json:"count"`
+	Items []T    `json:"items"`
+}
+
+// pageCount Размер очередной страницы с учетом ограничения maxItems.
+func (c *VKClient) pageCount(maxPageSize, fetched int) int {
+	count := min(c.pageSize, maxPageSize)
+	if c.maxItems > 0 {
+		count = min(count, c.maxItems-fetched)
 	}
 
-	for _, baseURL := range c.baseURLs {
-		urlr, err := url.JoinPath(baseURL, getFollowersMethodName)
-		if err != nil {
-			return nil, err
-		}
+	return count
+}
 
-		if err := rest.GetRestClient(func() (errGet error) {
-			req := c.resty.R().
-				SetContext(ctx).
-				SetQueryParams(map[string]string{
-					"v":       apiVersion,
-					"user_id": userID,
-					"count":   strconv.FormatUint(count, 10),
-					"fields":  usersGetFields,
-				})
+// pageParams Параметры очередной страницы списочного метода. Возвращает false, если страниц больше нет.
+func (c *VKClient) pageParams(params map[string]string, maxPageSize, offset, fetched int, total uint64) (map[string]string, bool) {
+	if offset > 0 && uint64(offset) >= total {
+		return nil, false
+	}
 
-			resp, errGet = req.Get(urlr)
+	count := c.pageCount(maxPageSize, fetched)
+	if count <= 0 {
+		return nil, false
+	}
 
-			if errGet != nil {
-				return errGet
-			}
+	result := make(map[string]string, len(params)+2)
+	for k, v := range params {
+		result[k] = v
+	}
+	result["offset"] = strconv.Itoa(offset)
+	result["count"] = strconv.Itoa(count)
 
-			return nil
-		}); err != nil {
-			log.Error().Err(err).Send()
-			continue
-		}
+	return result, true
+}
 
-		body := resp.Body()
-		if body == nil {
-			log.Warn().Msg("body == nil")
-			continue
+// paginate Обходит списочный метод через offset/count, пока не будут получены все элементы
+// или не будет достигнуто ограничение maxItems.
+func paginate[T any](ctx context.Context, c *VKClient, method string, params map[string]string, maxPageSize int) ([]T, uint64, error) {
+	return paginateFrom[T](ctx, c, method, params, maxPageSize, 0, nil, 0)
+}
+
+// paginateFrom Продолжает обход списочного метода с offset, дополняя уже полученные items.
+func paginateFrom[T any](ctx context.Context, c *VKClient, method string, params map[string]string, maxPageSize int,
+	offset int, items []T, total uint64) ([]T, uint64, error) {
+	for {
+		pageParams, ok := c.pageParams(params, maxPageSize, offset, len(items), total)
+		if !ok {
+			break
 		}
 
-		if err := sonic.Unmarshal(body, &response); err != nil {
-			log.Error().Err(err).Send()
-			continue
+		p, err := call[Page[T]](ctx, c, method, pageParams)
+		if err != nil {
+			return nil, 0, err
 		}
 
-		return response.Params.Followers, nil
+		total = p.Count
+		items = append(items, p.Items...)
+
+		// VK может вернуть меньше count элементов (например, удаленные страницы отфильтрованы),
+		// поэтому следующий offset считается по запрошенному count, а не по длине страницы.
+		count, _ := strconv.Atoi(pageParams["count"])
+		offset += count
+
+		if len(p.Items) == 0 {
+			break
+		}
 	}
 
-	return nil, err
+	return items, total, nil
 }
 
-func (c *VKClient) GetSubscriptions(ctx context.Context, id uint64) (subscriptions models.Subscriptions, err error) {
-	userID := strconv.FormatUint(id, 10)
-	var resp *resty.Response
-
-	type userGroup struct {
-		ID         uint64      `json:"id"`
-		Type       string      `json:"type"`
-		Name       string      `json:"name"`
-		ScreenName string      `json:"screen_name"`
-		FirstName  string      `json:"first_name"`
-		LastName   string      `json:"last_name"`
-		Sex        byte        `json:"sex"`
-		City       models.City `json:"city"`
-	}
-	type respParams struct {
-		Count         uint64      `json:"count"`
-		Subscriptions []userGroup `json:"items"`
-	}
-	var response struct {
-		Params respParams `json:"response"`
-	}
+type envelope[T any] struct {
+	Response      T         `json:"response"`
+	Error         *VKError  `json:"error"`
+	ExecuteErrors []VKError `json:"execute_errors"`
+}
+
+// call Выполняет метод VK API, перебирая базовые адреса, и декодирует поле response в T.
+// Ошибка из поля error возвращается как *VKError.
+func call[T any](ctx context.Context, c *VKClient, method string, params map[string]string) (T, error) {
+	response, err := callEnvelope[T](ctx, c, method, params)
+	return response.Response, err
+}
+
+func callEnvelope[T any](ctx context.Context, c *VKClient, method string, params map[string]string) (envelope[T], error) {
+	var (
+		response envelope[T]
+		lastErr  error
+	)
 
 	for _, baseURL := range c.baseURLs {
-		urlr, err := url.JoinPath(baseURL, getSubscriptionsMethodName)
+		urlr, err := url.JoinPath(baseURL, method)
 		if err != nil {
-			return subscriptions, err
+			return response, err
 		}
 
-		if err := rest.GetRestClient(func() (errGet error) {
+		if err := rest.GetRestClient(func() error {
+			t, waited, err := c.tokens.acquire(ctx)
+			if err != nil {
+				return retry.Unrecoverable(err)
+			} else if waited > 0 {
+				log.Debug().Msgf("%s: ожидание лимита запросов %s", method, waited)
+				observeWait(ctx, strings.TrimPrefix(method, "method/"), waited)
+			}
+
 			req := c.resty.R().
 				SetContext(ctx).
-				SetQueryParams(map[string]string{
-					"v":        apiVersion,
-					"user_id":  userID,
-					"extended": "1",
-					"count":    strconv.FormatUint(count, 10),
-				})
-
-			resp, errGet = req.Get(urlr)
-
+				SetAuthToken(t.value).
+				SetQueryParam("v", apiVersion)
+
+			var (
+				resp   *resty.Response
+				errGet error
+			)
+			// Код execute может не поместиться в query string.
+			if method == executeMethodName {
+				resp, errGet = req.SetFormData(params).Post(urlr)
+			} else {
+				resp, errGet = req.SetQueryParams(params).Get(urlr)
+			}
 			if errGet != nil {
 				return errGet
 			}
 
+			if resp.IsError() {
+				return fmt.Errorf("%s: err resp status: %s", method, resp.Status())
+			}
+
+			body := resp.Body()
+			if body == nil {
+				log.Warn().Msgf("%s: body == nil", method)
+				return fmt.Errorf("%s: empty response body", method)
+			}
+
+			response = envelope[T]{}
+			if err := sonic.Unmarshal(body, &response); err != nil {
+				return fmt.Errorf("%s: %w", method, err)
+			}
+
+			if response.Error != nil {
+				if c.tokens.report(t, response.Error) {
+					log.Warn().Err(response.Error).Msgf("%s: токен %s отправлен на карантин", method, maskToken(t.value))
+					return tokenRotateError{response.Error}
+				}
+
+				return response.Error
+			}
+
 			return nil
 		}); err != nil {
-			log.Error().Err(err).Send()
-			continue
-		}
-
-		body := resp.Body()
-		if body == nil {
-			log.Warn().Msg("c.GetUsers: body == nil")
-			continue
-		}
+			// Ошибку VK API другой базовый адрес не исправит.
+			if vkErr, ok := AsVKError(err); ok {
+				return response, vkErr
+			}
 
-		if err := sonic.Unmarshal(body, &response); err != nil {
 			log.Error().Err(err).Send()
+			lastErr = err
 			continue
 		}
 
-		for _, userGroup := range response.Params.Subscriptions {
-			switch userGroup.Type {
-			case "profile":
-				subscriptions.Users = append(subscriptions.Users, models.User{
-					ID:         userGroup.ID,
-					ScreenName: userGroup.ScreenName,
-					FirstName:  userGroup.FirstName,
-					LastName:   userGroup.LastName,
-					Sex:        userGroup.Sex,
-					City:       userGroup.City,
-				})
-			case "page":
-				subscriptions.Groups = append(subscriptions.Groups, models.Group{
-					ID:         userGroup.ID,
-					Name:       userGroup.Name,
-					ScreenName: userGroup.ScreenName,
-				})
-			}
-		}
+		return response, nil
+	}
 
-		log.Info().Msgf("Обошел подписки: %+v", subscriptions)
-		return subscriptions, nil
+	if lastErr == nil {
+		lastErr = fmt.Errorf("%s: no base urls configured", method)
 	}
 
-	return subscriptions, err
+	return response, lastErr
 }
 
 func (c *VKClient) parseUserIDs(ids ...uint64) (string, error) {
@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newFollowersServer(t *testing.T, total int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+
+		items := ""
+		for i := offset; i < offset+count && i < total; i++ {
+			if items != "" {
+				items += ","
+			}
+			items += fmt.Sprintf(`{"id":%d}`, i+1)
+		}
+
+		fmt.Fprintf(w, `{"response":{"count":%d,"items":[%s]}}`, total, items)
+	}))
+}
+
+func TestGetFollowersPagination(t *testing.T) {
+	srv := newFollowersServer(t, 7)
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithPageSize(3), WithRateLimit(0, 0))
+
+	followers, total, err := c.GetFollowers(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), total)
+	require.Len(t, followers, 7)
+	require.Equal(t, uint64(7), followers[6].ID)
+}
+
+func TestGetFollowersMaxItems(t *testing.T) {
+	srv := newFollowersServer(t, 10)
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithPageSize(3), WithMaxItems(4), WithRateLimit(0, 0))
+
+	followers, total, err := c.GetFollowers(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), total)
+	require.Len(t, followers, 4)
+}
+
+func TestVKErrorDecoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":{"error_code":30,"error_msg":"This profile is private","request_params":[{"key":"user_id","value":"1"}]}}`)
+	}))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithRateLimit(0, 0))
+
+	_, _, err := c.GetFollowers(context.Background(), 1)
+	require.Error(t, err)
+	require.True(t, IsAccessDenied(err))
+	require.False(t, IsRateLimit(err))
+
+	vkErr, ok := AsVKError(err)
+	require.True(t, ok)
+	require.Equal(t, ErrCodePrivateProfile, vkErr.Code)
+	require.Equal(t, []RequestParam{{Key: "user_id", Value: "1"}}, vkErr.RequestParams)
+}
+
+// TestServerErrorRetriesAcquireToken Каждый повтор после 5xx снова проходит через пул токенов и лимит.
+func TestServerErrorRetriesAcquireToken(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		fmt.Fprint(w, `{"response":[{"id":1}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"first", "second"}, WithRateLimit(0, 0))
+
+	users, err := c.GetUsers(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, 3, calls)
+
+	var requests uint64
+	for _, status := range c.TokensStatus() {
+		requests += status.Requests
+	}
+	require.Equal(t, uint64(3), requests)
+}
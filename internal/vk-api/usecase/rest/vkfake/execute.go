@@ -0,0 +1,136 @@
+package vkfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type executeCall struct {
+	method string
+	params map[string]string
+}
+
+// execute Выполняет вызовы из кода execute. Неудавшийся вызов возвращает false,
+// а его ошибка добавляется в execute_errors.
+func (s *Server) execute(token, code string) ([]any, []*apiError, *apiError) {
+	s.calls[MethodExecute]++
+
+	params := map[string]string{"code": code}
+	if apiErr := s.fault(MethodExecute, token, params); apiErr != nil {
+		return nil, nil, apiErr
+	}
+
+	calls, err := parseExecuteCode(code)
+	if err != nil {
+		return nil, nil, newAPIError(MethodExecute, CodeExecuteFailed, err.Error(), params)
+	}
+
+	if len(calls) > maxExecuteCalls {
+		return nil, nil, newAPIError(MethodExecute, CodeExecuteFailed, "Too many API calls in execute", params)
+	}
+
+	response := make([]any, 0, len(calls))
+	var executeErrors []*apiError
+	for _, c := range calls {
+		result, apiErr := s.call(c.method, token, c.params)
+		if apiErr != nil {
+			apiErr.Method = c.method
+			apiErr.RequestParams = nil
+
+			response = append(response, false)
+			executeErrors = append(executeErrors, apiErr)
+			continue
+		}
+
+		response = append(response, result)
+	}
+
+	return response, executeErrors, nil
+}
+
+// parseExecuteCode Разбирает код, который формирует VKClient: return [API.method({"k":"v",...}),...];
+func parseExecuteCode(code string) ([]executeCall, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(code), "return")
+	if !ok {
+		return nil, fmt.Errorf("code must start with return")
+	}
+
+	rest = strings.TrimSuffix(strings.TrimSpace(rest), ";")
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+		return nil, fmt.Errorf("code must return an array of API calls")
+	}
+	rest = rest[1 : len(rest)-1]
+
+	var calls []executeCall
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return calls, nil
+		}
+
+		if len(calls) > 0 {
+			if rest, ok = strings.CutPrefix(rest, ","); !ok {
+				return nil, fmt.Errorf("expected , after call %d", len(calls))
+			}
+			rest = strings.TrimSpace(rest)
+		}
+
+		var c executeCall
+		var err error
+		c, rest, err = parseExecuteCall(rest)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", len(calls), err)
+		}
+
+		calls = append(calls, c)
+	}
+}
+
+func parseExecuteCall(code string) (executeCall, string, error) {
+	rest, ok := strings.CutPrefix(code, "API.")
+	if !ok {
+		return executeCall{}, "", fmt.Errorf("expected API.")
+	}
+
+	method, rest, ok := strings.Cut(rest, "(")
+	if !ok || method == "" {
+		return executeCall{}, "", fmt.Errorf("expected API.method(")
+	}
+
+	c := executeCall{method: method, params: make(map[string]string)}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, ")") {
+		dec := json.NewDecoder(strings.NewReader(rest))
+		dec.UseNumber()
+
+		var args map[string]any
+		if err := dec.Decode(&args); err != nil {
+			return executeCall{}, "", fmt.Errorf("%s arguments: %w", method, err)
+		}
+		rest = strings.TrimSpace(rest[dec.InputOffset():])
+
+		for key, value := range args {
+			switch v := value.(type) {
+			case string:
+				c.params[key] = v
+			case json.Number:
+				c.params[key] = v.String()
+			case bool:
+				c.params[key] = strconv.FormatBool(v)
+			default:
+				return executeCall{}, "", fmt.Errorf("%s argument %s: unsupported type %T", method, key, value)
+			}
+		}
+	}
+
+	rest, ok = strings.CutPrefix(rest, ")")
+	if !ok {
+		return executeCall{}, "", fmt.Errorf("expected ) after %s arguments", method)
+	}
+
+	return c, rest, nil
+}
@@ -0,0 +1,114 @@
+package vkfake
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"slices"
+)
+
+// Subscriptions Подписки пользователя на других пользователей и группы.
+type Subscriptions struct {
+	Users  []uint64
+	Groups []uint64
+}
+
+// Graph Граф VK, который отдает фейковый сервер. Списки отдаются в порядке добавления.
+type Graph struct {
+	Users         map[uint64]models.User
+	Groups        map[uint64]models.Group
+	Followers     map[uint64][]uint64
+	Subscriptions map[uint64]Subscriptions
+}
+
+// NewGraph Граф из выгрузки пользователей в формате models.User. Вложенные подписчики
+// и подписки тоже становятся вершинами графа.
+func NewGraph(users ...models.User) *Graph {
+	g := &Graph{
+		Users:         make(map[uint64]models.User),
+		Groups:        make(map[uint64]models.Group),
+		Followers:     make(map[uint64][]uint64),
+		Subscriptions: make(map[uint64]Subscriptions),
+	}
+
+	for _, user := range users {
+		g.AddUser(user)
+	}
+
+	return g
+}
+
+// AddUser Добавляет пользователя вместе с вложенными подписчиками и подписками.
+// Профиль без имени не затирает уже добавленный.
+func (g *Graph) AddUser(user models.User) {
+	if _, ok := g.Users[user.ID]; !ok || user.FirstName != "" || user.LastName != "" {
+		g.Users[user.ID] = models.User{
+			ID:         user.ID,
+			ScreenName: user.ScreenName,
+			FirstName:  user.FirstName,
+			LastName:   user.LastName,
+			Sex:        user.Sex,
+			City:       user.City,
+		}
+	}
+
+	for _, follower := range user.Followers {
+		g.AddUser(follower)
+		g.Follow(follower.ID, user.ID)
+	}
+
+	for _, subscription := range user.Subscriptions.Users {
+		g.AddUser(subscription)
+		g.Subscribe(user.ID, subscription.ID)
+	}
+
+	for _, group := range user.Subscriptions.Groups {
+		g.AddGroup(group)
+		g.SubscribeGroup(user.ID, group.ID)
+	}
+}
+
+func (g *Graph) AddGroup(group models.Group) {
+	g.Groups[group.ID] = group
+}
+
+// Follow follower появляется в users.getFollowers пользователя userID.
+func (g *Graph) Follow(followerID, userID uint64) {
+	g.ensureUser(followerID)
+	g.ensureUser(userID)
+	g.Followers[userID] = appendUnique(g.Followers[userID], followerID)
+}
+
+// Subscribe Пользователь targetID появляется в users.getSubscriptions пользователя userID.
+func (g *Graph) Subscribe(userID, targetID uint64) {
+	g.ensureUser(userID)
+	g.ensureUser(targetID)
+
+	s := g.Subscriptions[userID]
+	s.Users = appendUnique(s.Users, targetID)
+	g.Subscriptions[userID] = s
+}
+
+// SubscribeGroup Группа groupID появляется в users.getSubscriptions пользователя userID.
+func (g *Graph) SubscribeGroup(userID, groupID uint64) {
+	g.ensureUser(userID)
+	if _, ok := g.Groups[groupID]; !ok {
+		g.Groups[groupID] = models.Group{ID: groupID}
+	}
+
+	s := g.Subscriptions[userID]
+	s.Groups = appendUnique(s.Groups, groupID)
+	g.Subscriptions[userID] = s
+}
+
+func (g *Graph) ensureUser(id uint64) {
+	if _, ok := g.Users[id]; !ok {
+		g.Users[id] = models.User{ID: id}
+	}
+}
+
+func appendUnique(ids []uint64, id uint64) []uint64 {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+
+	return append(ids, id)
+}
@@ -0,0 +1,190 @@
+package vkfake
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"strconv"
+	"strings"
+)
+
+type city struct {
+	Title string `json:"title"`
+}
+
+// item Профиль пользователя или группа в формате VK API.
+type item struct {
+	ID         uint64 `json:"id"`
+	Type       string `json:"type,omitempty"`
+	FirstName  string `json:"first_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+	Name       string `json:"name,omitempty"`
+	ScreenName string `json:"screen_name,omitempty"`
+	Sex        *byte  `json:"sex,omitempty"`
+	City       *city  `json:"city,omitempty"`
+}
+
+func profileItem(user models.User) item {
+	profile := item{
+		ID:         user.ID,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		ScreenName: user.ScreenName,
+		Sex:        &user.Sex,
+	}
+
+	if user.City.Title != "" {
+		profile.City = &city{Title: user.City.Title}
+	}
+
+	return profile
+}
+
+func groupItem(group models.Group) item {
+	return item{
+		ID:         group.ID,
+		Type:       "page",
+		Name:       group.Name,
+		ScreenName: group.ScreenName,
+	}
+}
+
+type page struct {
+	Count int    `json:"count"`
+	Items []item `json:"items"`
+}
+
+type idsPage struct {
+	Count int      `json:"count"`
+	Items []uint64 `json:"items"`
+}
+
+// usersGet Пользователи по id или коротким именам. Неизвестные пропускаются.
+func (s *Server) usersGet(params map[string]string) []item {
+	users := make([]item, 0)
+	for _, key := range strings.Split(params["user_ids"], ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		if user, ok := s.findUser(key); ok {
+			users = append(users, profileItem(user))
+		}
+	}
+
+	return users
+}
+
+func (s *Server) findUser(key string) (models.User, bool) {
+	if id, err := strconv.ParseUint(key, 10, 64); err == nil {
+		user, ok := s.graph.Users[id]
+		return user, ok
+	}
+
+	for _, user := range s.graph.Users {
+		if user.ScreenName == key {
+			return user, true
+		}
+	}
+
+	return models.User{}, false
+}
+
+func (s *Server) getFollowers(method string, params map[string]string) (any, *apiError) {
+	user, apiErr := s.paramUser(method, params)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	offset, count, apiErr := pageBounds(method, params, maxFollowersCount)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	followers := s.graph.Followers[user.ID]
+
+	p := page{Count: len(followers), Items: make([]item, 0)}
+	for _, id := range window(followers, offset, count) {
+		p.Items = append(p.Items, profileItem(s.graph.Users[id]))
+	}
+
+	return p, nil
+}
+
+func (s *Server) getSubscriptions(method string, params map[string]string) (any, *apiError) {
+	user, apiErr := s.paramUser(method, params)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	subscriptions := s.graph.Subscriptions[user.ID]
+
+	if params["extended"] != "1" {
+		return map[string]idsPage{
+			"users":  {Count: len(subscriptions.Users), Items: append(make([]uint64, 0), subscriptions.Users...)},
+			"groups": {Count: len(subscriptions.Groups), Items: append(make([]uint64, 0), subscriptions.Groups...)},
+		}, nil
+	}
+
+	offset, count, apiErr := pageBounds(method, params, maxSubscriptionsCount)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// Сначала пользователи, затем группы.
+	items := make([]item, 0, len(subscriptions.Users)+len(subscriptions.Groups))
+	for _, id := range subscriptions.Users {
+		profile := profileItem(s.graph.Users[id])
+		profile.Type = "profile"
+		items = append(items, profile)
+	}
+	for _, id := range subscriptions.Groups {
+		items = append(items, groupItem(s.graph.Groups[id]))
+	}
+
+	return page{Count: len(items), Items: append(make([]item, 0), window(items, offset, count)...)}, nil
+}
+
+func (s *Server) paramUser(method string, params map[string]string) (models.User, *apiError) {
+	id, err := strconv.ParseUint(params["user_id"], 10, 64)
+	if err != nil {
+		return models.User{}, newAPIError(method, CodeInvalidUserID, "", params)
+	}
+
+	user, ok := s.graph.Users[id]
+	if !ok {
+		return models.User{}, newAPIError(method, CodeInvalidUserID, "", params)
+	}
+
+	return user, nil
+}
+
+// pageBounds Параметры offset и count. count больше maxCount урезается, как в VK API.
+func pageBounds(method string, params map[string]string, maxCount int) (int, int, *apiError) {
+	offset, count := 0, defaultListCount
+
+	if v, ok := params["offset"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, newAPIError(method, CodeInvalidParam, "", params)
+		}
+		offset = n
+	}
+
+	if v, ok := params["count"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, newAPIError(method, CodeInvalidParam, "", params)
+		}
+		count = n
+	}
+
+	return offset, min(count, maxCount), nil
+}
+
+func window[T any](items []T, offset, count int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+
+	return items[offset:min(offset+count, len(items))]
+}
@@ -0,0 +1,332 @@
+package vkfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MethodUsersGet         = "users.get"
+	MethodGetFollowers     = "users.getFollowers"
+	MethodGetSubscriptions = "users.getSubscriptions"
+	MethodExecute          = "execute"
+
+	// Ограничения VK API на count и число вызовов внутри execute.
+	maxFollowersCount     = 1000
+	maxSubscriptionsCount = 200
+	defaultListCount      = 100
+	maxExecuteCalls       = 25
+
+	CodeUnknown          = 1
+	CodeUnknownMethod    = 3
+	CodeInvalidToken     = 5
+	CodeTooManyRequests  = 6
+	CodeInternalServer   = 10
+	CodeExecuteFailed    = 13
+	CodeAccessDenied     = 15
+	CodeUserDeleted      = 18
+	CodeRateLimitReached = 29
+	CodePrivateProfile   = 30
+	CodeInvalidParam     = 100
+	CodeInvalidUserID    = 113
+)
+
+var errorMessages = map[int]string{
+	CodeUnknown:          "Unknown error occurred",
+	CodeUnknownMethod:    "Unknown method passed",
+	CodeInvalidToken:     "User authorization failed: invalid access_token",
+	CodeTooManyRequests:  "Too many requests per second",
+	CodeInternalServer:   "Internal server error",
+	CodeExecuteFailed:    "Runtime error occurred during code invocation",
+	CodeAccessDenied:     "Access denied",
+	CodeUserDeleted:      "User was deleted or banned",
+	CodeRateLimitReached: "Rate limit reached",
+	CodePrivateProfile:   "This profile is private",
+	CodeInvalidParam:     "One of the parameters specified was missing or invalid",
+	CodeInvalidUserID:    "Invalid user id",
+}
+
+// Fault Сценарная ошибка VK API.
+type Fault struct {
+	// Method Метод без префикса method/, например users.getFollowers. Пустой - любой метод.
+	// Ошибка execute прерывает весь запрос, ошибка вложенного метода попадает в execute_errors.
+	Method string
+	// UserID Пользователь из параметра user_id или user_ids, 0 - любой.
+	UserID uint64
+	// Token Токен запроса, пустой - любой.
+	Token   string
+	Code    int
+	Message string
+	// Times Сколько раз сработать, 0 - всегда.
+	Times int
+}
+
+type Option func(*Server)
+
+// WithLatency Задержка перед каждым ответом.
+func WithLatency(latency time.Duration) Option {
+	return func(s *Server) {
+		s.latency = latency
+	}
+}
+
+// WithRateLimit Не больше perSecond запросов в секунду на токен, сверх - ошибка 6.
+// Вызов execute считается одним запросом.
+func WithRateLimit(perSecond int) Option {
+	return func(s *Server) {
+		s.rateLimit = perSecond
+	}
+}
+
+// WithTokens Допустимые токены, остальные получают ошибку 5. По умолчанию подходит любой токен.
+func WithTokens(tokens ...string) Option {
+	return func(s *Server) {
+		s.tokens = tokens
+	}
+}
+
+// Server Фейковый VK API поверх httptest.Server. Адрес сервера передается в VKClient как базовый.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	graph     *Graph
+	latency   time.Duration
+	rateLimit int
+	tokens    []string
+	faults    []*Fault
+	calls     map[string]int
+	requests  map[string][]time.Time
+}
+
+func NewServer(graph *Graph, opts ...Option) *Server {
+	if graph == nil {
+		graph = NewGraph()
+	}
+
+	s := &Server{
+		graph:    graph,
+		calls:    make(map[string]int),
+		requests: make(map[string][]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/method/{method}", s.handle)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// AddFault Добавляет сценарную ошибку. Ошибки проверяются в порядке добавления.
+func (s *Server) AddFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// Update Изменяет граф во время работы сервера.
+func (s *Server) Update(update func(g *Graph)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update(s.graph)
+}
+
+// Calls Число вызовов метода, включая вызовы внутри execute.
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[method]
+}
+
+// apiError Ошибка в формате VK API.
+type apiError struct {
+	Method        string         `json:"method,omitempty"`
+	Code          int            `json:"error_code"`
+	Message       string         `json:"error_msg"`
+	RequestParams []requestParam `json:"request_params,omitempty"`
+}
+
+type requestParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func newAPIError(method string, code int, message string, params map[string]string) *apiError {
+	if message == "" {
+		message = errorMessages[code]
+	}
+
+	e := &apiError{Code: code, Message: message}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	e.RequestParams = append(e.RequestParams, requestParam{Key: "method", Value: method})
+	for _, key := range keys {
+		e.RequestParams = append(e.RequestParams, requestParam{Key: key, Value: params[key]})
+	}
+
+	return e
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	method := r.PathValue("method")
+
+	if s.latency > 0 {
+		select {
+		case <-time.After(s.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, map[string]any{"error": newAPIError(method, CodeInvalidParam, err.Error(), nil)})
+		return
+	}
+
+	params := make(map[string]string, len(r.Form))
+	for key := range r.Form {
+		if key != "access_token" {
+			params[key] = r.Form.Get(key)
+		}
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.Form.Get("access_token")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if apiErr := s.admit(method, token, params); apiErr != nil {
+		writeJSON(w, map[string]any{"error": apiErr})
+		return
+	}
+
+	if method == MethodExecute {
+		response, executeErrors, apiErr := s.execute(token, params["code"])
+		if apiErr != nil {
+			writeJSON(w, map[string]any{"error": apiErr})
+			return
+		}
+
+		body := map[string]any{"response": response}
+		if len(executeErrors) > 0 {
+			body["execute_errors"] = executeErrors
+		}
+
+		writeJSON(w, body)
+		return
+	}
+
+	response, apiErr := s.call(method, token, params)
+	if apiErr != nil {
+		writeJSON(w, map[string]any{"error": apiErr})
+		return
+	}
+
+	writeJSON(w, map[string]any{"response": response})
+}
+
+// admit Проверяет токен и ограничение частоты запросов.
+func (s *Server) admit(method, token string, params map[string]string) *apiError {
+	if len(s.tokens) > 0 && !slices.Contains(s.tokens, token) {
+		return newAPIError(method, CodeInvalidToken, "", params)
+	}
+
+	if s.rateLimit <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	recent := slices.DeleteFunc(s.requests[token], func(t time.Time) bool {
+		return now.Sub(t) >= time.Second
+	})
+
+	if len(recent) >= s.rateLimit {
+		s.requests[token] = recent
+		return newAPIError(method, CodeTooManyRequests, "", params)
+	}
+
+	s.requests[token] = append(recent, now)
+	return nil
+}
+
+// call Выполняет метод VK API по графу.
+func (s *Server) call(method, token string, params map[string]string) (any, *apiError) {
+	s.calls[method]++
+
+	if apiErr := s.fault(method, token, params); apiErr != nil {
+		return nil, apiErr
+	}
+
+	switch method {
+	case MethodUsersGet:
+		return s.usersGet(params), nil
+	case MethodGetFollowers:
+		return s.getFollowers(method, params)
+	case MethodGetSubscriptions:
+		return s.getSubscriptions(method, params)
+	}
+
+	return nil, newAPIError(method, CodeUnknownMethod, "", params)
+}
+
+// fault Первая подходящая сценарная ошибка.
+func (s *Server) fault(method, token string, params map[string]string) *apiError {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+
+		if f.Token != "" && f.Token != token {
+			continue
+		}
+
+		if f.UserID != 0 && !slices.Contains(paramUserIDs(params), strconv.FormatUint(f.UserID, 10)) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+
+		return newAPIError(method, f.Code, f.Message, params)
+	}
+
+	return nil
+}
+
+func paramUserIDs(params map[string]string) []string {
+	if id, ok := params["user_id"]; ok {
+		return []string{id}
+	}
+
+	return strings.Split(params["user_ids"], ",")
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
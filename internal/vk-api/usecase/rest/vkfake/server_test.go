@@ -0,0 +1,88 @@
+package vkfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseExecuteCode(t *testing.T) {
+	calls, err := parseExecuteCode(`return [API.users.get({"fields":"screen_name,sex,city","user_ids":"1,2"}),` +
+		`API.users.getFollowers({"count":"3","offset":0,"user_id":"1"}),API.users.get()];`)
+	require.NoError(t, err)
+	require.Equal(t, []executeCall{
+		{method: "users.get", params: map[string]string{"fields": "screen_name,sex,city", "user_ids": "1,2"}},
+		{method: "users.getFollowers", params: map[string]string{"count": "3", "offset": "0", "user_id": "1"}},
+		{method: "users.get", params: map[string]string{}},
+	}, calls)
+
+	for _, code := range []string{
+		`[API.users.get({})];`,
+		`return [API.users.get({"a":"b"}) API.users.get({})];`,
+		`return [users.get({})];`,
+		`return [API.users.get({"a":[1]})];`,
+	} {
+		_, err := parseExecuteCode(code)
+		require.Error(t, err, code)
+	}
+}
+
+type envelope struct {
+	Response      json.RawMessage `json:"response"`
+	Error         *apiError       `json:"error"`
+	ExecuteErrors []apiError      `json:"execute_errors"`
+}
+
+func get(t *testing.T, s *Server, method string, params url.Values) envelope {
+	t.Helper()
+
+	resp, err := http.PostForm(fmt.Sprintf("%s/method/%s", s.URL, method), params)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var e envelope
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+	return e
+}
+
+func TestServer(t *testing.T) {
+	g := NewGraph(models.User{
+		ID:        1,
+		FirstName: "Root",
+		Followers: []models.User{{ID: 2}, {ID: 3}, {ID: 4}},
+		Subscriptions: models.Subscriptions{
+			Users:  []models.User{{ID: 2}},
+			Groups: []models.Group{{ID: 100, Name: "Group"}},
+		},
+	})
+
+	s := NewServer(g, WithTokens("good"))
+	defer s.Close()
+
+	e := get(t, s, MethodGetFollowers, url.Values{"access_token": {"bad"}, "user_id": {"1"}})
+	require.Equal(t, CodeInvalidToken, e.Error.Code)
+
+	e = get(t, s, MethodGetFollowers, url.Values{"access_token": {"good"}, "user_id": {"1"}, "offset": {"1"}, "count": {"5"}})
+	require.Nil(t, e.Error)
+	require.JSONEq(t, `{"count":3,"items":[{"id":3,"sex":0},{"id":4,"sex":0}]}`, string(e.Response))
+
+	e = get(t, s, MethodGetSubscriptions, url.Values{"access_token": {"good"}, "user_id": {"1"}, "extended": {"1"}})
+	require.JSONEq(t, `{"count":2,"items":[{"id":2,"type":"profile","sex":0},{"id":100,"type":"page","name":"Group"}]}`, string(e.Response))
+
+	s.AddFault(Fault{Method: MethodGetFollowers, UserID: 2, Code: CodePrivateProfile, Times: 1})
+
+	code := `return [API.users.getFollowers({"user_id":"2"}),API.users.getFollowers({"user_id":"2"})];`
+	e = get(t, s, MethodExecute, url.Values{"access_token": {"good"}, "code": {code}})
+	require.Nil(t, e.Error)
+	require.JSONEq(t, `[false,{"count":0,"items":[]}]`, string(e.Response))
+	require.Len(t, e.ExecuteErrors, 1)
+	require.Equal(t, MethodGetFollowers, e.ExecuteErrors[0].Method)
+	require.Equal(t, CodePrivateProfile, e.ExecuteErrors[0].Code)
+
+	require.Equal(t, 1, s.Calls(MethodExecute))
+	require.Equal(t, 3, s.Calls(MethodGetFollowers))
+}
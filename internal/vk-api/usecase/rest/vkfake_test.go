@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest/vkfake"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newFakeGraph() *vkfake.Graph {
+	return vkfake.NewGraph(
+		models.User{
+			ID:        1,
+			FirstName: "Root",
+			Followers: []models.User{{ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}},
+			Subscriptions: models.Subscriptions{
+				Users:  []models.User{{ID: 2}, {ID: 3}},
+				Groups: []models.Group{{ID: 100, Name: "First"}, {ID: 200, Name: "Second"}},
+			},
+		},
+		models.User{ID: 2, FirstName: "Private", Followers: []models.User{{ID: 1}}},
+	)
+}
+
+func TestGetUsersWithConnectionsFakeVK(t *testing.T) {
+	srv := vkfake.NewServer(newFakeGraph())
+	defer srv.Close()
+
+	srv.AddFault(vkfake.Fault{Method: vkfake.MethodGetFollowers, UserID: 2, Code: vkfake.CodePrivateProfile})
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithPageSize(2), WithRateLimit(0, 0))
+
+	conns, err := c.GetUsersWithConnections(context.Background(), 1, 2, 42)
+	require.NoError(t, err)
+	require.Len(t, conns, 2)
+
+	root := conns[0]
+	require.NoError(t, root.FollowersErr)
+	require.NoError(t, root.SubscriptionsErr)
+	require.Equal(t, "Root", root.User.FirstName)
+	require.Len(t, root.User.Followers, 5)
+	require.Equal(t, uint64(5), root.FollowersCount)
+	require.Len(t, root.User.Subscriptions.Users, 2)
+	require.Len(t, root.User.Subscriptions.Groups, 2)
+	require.Equal(t, "Second", root.User.Subscriptions.Groups[1].Name)
+
+	require.True(t, IsAccessDenied(conns[1].FollowersErr))
+	require.NoError(t, conns[1].SubscriptionsErr)
+
+	// Первые страницы всех трех пользователей приходят одним execute, остальные догружаются обычными запросами.
+	require.Equal(t, 1, srv.Calls(vkfake.MethodExecute))
+	require.Equal(t, 3+2, srv.Calls(vkfake.MethodGetFollowers))
+	require.Equal(t, 3+1, srv.Calls(vkfake.MethodGetSubscriptions))
+}
+
+func TestTemporaryErrorRetriedFakeVK(t *testing.T) {
+	srv := vkfake.NewServer(newFakeGraph())
+	defer srv.Close()
+
+	srv.AddFault(vkfake.Fault{Method: vkfake.MethodUsersGet, Code: vkfake.CodeInternalServer, Times: 2})
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithRateLimit(0, 0))
+
+	users, err := c.GetUsers(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, 3, srv.Calls(vkfake.MethodUsersGet))
+}
+
+func TestRateLimitFakeVK(t *testing.T) {
+	srv := vkfake.NewServer(newFakeGraph(), vkfake.WithRateLimit(5), vkfake.WithLatency(time.Millisecond))
+	defer srv.Close()
+
+	c := NewVKClient([]string{srv.URL}, []string{"token"}, WithRateLimit(4, 1))
+
+	started := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := c.GetUsers(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	// Без ограничения на клиенте шестой запрос за секунду получил бы ошибку 6.
+	require.GreaterOrEqual(t, time.Since(started), 1200*time.Millisecond)
+	require.Equal(t, 6, srv.Calls(vkfake.MethodUsersGet))
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+)
+
+const (
+	DefaultStatsLimit = 5
+	MaxStatsLimit     = 100
+)
+
+func checkStatsLimit(limit int) error {
+	if limit < 1 || limit > MaxStatsLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxStatsLimit)
+	}
+
+	return nil
+}
+
+func (uc *UserUsecase) GetUsersCount(ctx context.Context) (int, error) {
+	return uc.repo.GetUsersCount(ctx)
+}
+
+func (uc *UserUsecase) GetGroupsCount(ctx context.Context) (int, error) {
+	return uc.repo.GetGroupsCount(ctx)
+}
+
+// GetCounts Число пользователей и групп.
+func (uc *UserUsecase) GetCounts(ctx context.Context) (models.Counts, error) {
+	users, err := uc.repo.GetUsersCount(ctx)
+	if err != nil {
+		return models.Counts{}, fmt.Errorf("uc.repo.GetUsersCount: %w", err)
+	}
+
+	groups, err := uc.repo.GetGroupsCount(ctx)
+	if err != nil {
+		return models.Counts{}, fmt.Errorf("uc.repo.GetGroupsCount: %w", err)
+	}
+
+	return models.Counts{Users: users, Groups: groups}, nil
+}
+
+func (uc *UserUsecase) GetTopUsersByFollowersCount(ctx context.Context, limit int) ([]models.TopUser, error) {
+	if err := checkStatsLimit(limit); err != nil {
+		return nil, err
+	}
+
+	users, err := uc.repo.GetTopUsersByFollowersCount(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("uc.repo.GetTopUsersByFollowersCount: %w", err)
+	}
+
+	return nonNil(users), nil
+}
+
+func (uc *UserUsecase) GetTopGroupsBySubscribersCount(ctx context.Context, limit int) ([]models.TopGroup, error) {
+	if err := checkStatsLimit(limit); err != nil {
+		return nil, err
+	}
+
+	groups, err := uc.repo.GetTopGroupsBySubscribersCount(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("uc.repo.GetTopGroupsBySubscribersCount: %w", err)
+	}
+
+	return nonNil(groups), nil
+}
+
+func (uc *UserUsecase) GetUsersWithDifferentGroups(ctx context.Context, limit int) ([]models.User, error) {
+	if err := checkStatsLimit(limit); err != nil {
+		return nil, err
+	}
+
+	users, err := uc.repo.GetUsersWithDifferentGroups(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("uc.repo.GetUsersWithDifferentGroups: %w", err)
+	}
+
+	return nonNil(users), nil
+}
+
+// nonNil Пустой список вместо nil, чтобы в JSON был [], а не null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+
+	return items
+}
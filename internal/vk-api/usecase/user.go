@@ -2,195 +2,193 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
-	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/neo4j"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/crawler"
 	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
 	"github.com/rs/zerolog/log"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const requestTimeout = 60 * time.Second
 
+var ErrInvalidSeeds = errors.New("invalid crawl seeds")
+
 type UserUsecase struct {
-	client    *rest.VKClient
-	neo4jRepo *neo4j.UserNeo4jRepo
+	client VKAPI
+	repo   GraphRepository
+	crawls *crawler.Manager
 }
 
-func NewUserUsecase(client *rest.VKClient, neo4jRepo *neo4j.UserNeo4jRepo) *UserUsecase {
-	return &UserUsecase{client: client, neo4jRepo: neo4jRepo}
+func NewUserUsecase(client VKAPI, repo GraphRepository, crawls *crawler.Manager) *UserUsecase {
+	return &UserUsecase{client: client, repo: repo, crawls: crawls}
 }
 
 func (uc *UserUsecase) CreateIndexes(ctx context.Context) error {
-	return uc.neo4jRepo.CreateIndexes(ctx)
+	return uc.repo.CreateIndexes(ctx)
+}
+
+func (uc *UserUsecase) GetTokensStatus() []rest.TokenStatus {
+	return uc.client.TokensStatus()
 }
 
 func (uc *UserUsecase) GetUser(userID uint64) (models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
 	defer cancel()
 
-	user, err := uc.client.GetUsers(ctx, userID)
+	// users.get, users.getFollowers и users.getSubscriptions уходят одним запросом execute.
+	users, err := uc.client.GetUsersWithConnections(ctx, userID)
 	if err != nil {
-		return models.User{}, fmt.Errorf("uc.client.GetUsers: %w", err)
+		return models.User{}, fmt.Errorf("uc.client.GetUsersWithConnections: %w", err)
 	}
 
-	if len(user) == 0 {
-		return models.User{}, fmt.Errorf("uc.client.GetUsers: user not found")
+	if len(users) == 0 {
+		return models.User{}, fmt.Errorf("uc.client.GetUsersWithConnections: user not found")
 	}
 
-	time.Sleep(time.Second)
+	conn := users[0]
 
-	followers, err := uc.client.GetFollowers(ctx, userID)
-	if err != nil {
+	// Закрытый или удаленный профиль не должен прерывать обход: оставляем списки пустыми.
+	if err := conn.FollowersErr; err != nil && !isSkippableVKError(err) {
 		return models.User{}, fmt.Errorf("uc.client.GetFollowers: %w", err)
 	}
 
-	user[0].Followers = followers
-
-	time.Sleep(time.Second)
-
-	subscriptions, err := uc.client.GetSubscriptions(ctx, userID)
-	if err != nil {
+	if err := conn.SubscriptionsErr; err != nil && !isSkippableVKError(err) {
 		return models.User{}, fmt.Errorf("uc.client.GetSubscriptions: %w", err)
 	}
 
-	user[0].Subscriptions = subscriptions
+	log.Info().Msgf("Обошел пользователя: %s %s", conn.User.FirstName, conn.User.LastName)
+	return conn.User, nil
+}
 
-	log.Info().Msgf("Обошел пользователя: %s %s", user[0].FirstName, user[0].LastName)
-	return user[0], nil
+func isSkippableVKError(err error) bool {
+	return rest.IsAccessDenied(err) || rest.IsUserDeleted(err)
 }
 
-func (uc *UserUsecase) GetUsersWithDepth(userID uint64, depth int) (models.User, error) {
-	if depth <= 0 {
-		return models.User{}, nil
-	}
+// Crawl Обходит граф VK в ширину от seeds, записывая найденных пользователей, группы и связи в репозиторий.
+func (uc *UserUsecase) Crawl(ctx context.Context, seeds []uint64, cfg crawler.Config) (crawler.Stats, error) {
+	return crawler.New(uc.client, uc.repo, cfg).Crawl(ctx, seeds)
+}
 
-	user, err := uc.GetUser(userID)
+// StartCrawl Запускает фоновый обход. Стартовые пользователи задаются VK id или короткими именами.
+func (uc *UserUsecase) StartCrawl(ctx context.Context, seeds []string, cfg crawler.Config) (crawler.JobInfo, error) {
+	ids, err := uc.resolveUserIDs(ctx, seeds)
 	if err != nil {
-		return models.User{}, fmt.Errorf("uc.GetUser: %w", err)
+		return crawler.JobInfo{}, err
 	}
 
-	for i := range user.Followers {
-		if user.Followers[i], err = uc.GetUsersWithDepth(user.Followers[i].ID, depth-1); err != nil {
-			return models.User{}, fmt.Errorf("uc.GetUsersWithDepth: %w", err)
+	return uc.crawls.Start(ids, cfg)
+}
+
+func (uc *UserUsecase) resolveUserIDs(ctx context.Context, seeds []string) ([]uint64, error) {
+	var (
+		ids         []uint64
+		screenNames []string
+	)
+
+	for _, seed := range seeds {
+		seed = strings.TrimSpace(seed)
+		if seed == "" {
+			continue
 		}
-		time.Sleep(time.Second)
-	}
 
-	for i := range user.Subscriptions.Users {
-		if user.Subscriptions.Users[i], err = uc.GetUsersWithDepth(user.Subscriptions.Users[i].ID, depth-1); err != nil {
-			return models.User{}, fmt.Errorf("uc.GetUsersWithDepth: %w", err)
+		if id, err := strconv.ParseUint(seed, 10, 64); err == nil {
+			ids = append(ids, id)
+			continue
 		}
-		time.Sleep(time.Second)
+
+		screenNames = append(screenNames, seed)
 	}
 
-	return user, nil
-}
+	if len(screenNames) == 0 {
+		return ids, nil
+	}
 
-func (uc *UserUsecase) SaveUser(ctx context.Context, user models.User) error {
-	if user.ID == 0 {
-		return nil
+	users, err := uc.client.GetUsersByScreenNames(ctx, screenNames...)
+	if err != nil {
+		return nil, fmt.Errorf("uc.client.GetUsersByScreenNames: %w", err)
 	}
 
-	log.Info().Msgf("Создание пользователя %s %s", user.FirstName, user.LastName)
-	if err := uc.neo4jRepo.CreateUser(ctx, user); err != nil {
-		return err
+	if len(users) != len(screenNames) {
+		return nil, fmt.Errorf("%w: resolved %d of %d screen names", ErrInvalidSeeds, len(users), len(screenNames))
 	}
 
-	for _, follower := range user.Followers {
-		if follower.ID == 0 {
-			continue
-		}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
 
-		if err := uc.SaveUser(ctx, follower); err != nil {
-			return err
-		}
+	return ids, nil
+}
 
-		log.Info().Msgf("Создание отношения (%s %s)->[:FOLLOW]->(%s %s)", follower.FirstName, follower.LastName, user.FirstName, user.LastName)
-		if err := uc.neo4jRepo.CreateFollowRelationship(ctx, follower, user); err != nil {
-			return err
-		}
-	}
+func (uc *UserUsecase) SubscribeCrawl(id string) (crawler.JobInfo, <-chan crawler.Event, func(), error) {
+	return uc.crawls.Subscribe(id)
+}
 
-	for _, subscription := range user.Subscriptions.Users {
-		if subscription.ID == 0 {
-			continue
-		}
+func (uc *UserUsecase) CancelCrawl(id string) (crawler.JobInfo, error) {
+	return uc.crawls.Cancel(id)
+}
 
-		if err := uc.SaveUser(ctx, subscription); err != nil {
-			return err
-		}
+func (uc *UserUsecase) PauseCrawl(id string) (crawler.JobInfo, error) {
+	return uc.crawls.Pause(id)
+}
 
-		log.Info().Msgf("Создание отношения (%s %s)->[:SUBSCRIBE]->(%s %s)", user.FirstName, user.LastName, subscription.FirstName, subscription.LastName)
-		if err := uc.neo4jRepo.CreateSubscribeUserUserRelationship(ctx, user, subscription); err != nil {
-			return err
-		}
-	}
+func (uc *UserUsecase) ResumeCrawl(id string) (crawler.JobInfo, error) {
+	return uc.crawls.Resume(id)
+}
 
-	for _, group := range user.Subscriptions.Groups {
-		if group.ID == 0 {
-			continue
-		}
+func (uc *UserUsecase) GetCrawl(id string) (crawler.JobInfo, error) {
+	return uc.crawls.Get(id)
+}
 
-		if err := uc.neo4jRepo.CreateGroup(ctx, group); err != nil {
-			return err
-		}
+func (uc *UserUsecase) ListCrawls() []crawler.JobInfo {
+	return uc.crawls.List()
+}
 
-		log.Info().Msgf("Создание отношения (%s %s)->[:SUBSCRIBE]->(%s)", user.FirstName, user.LastName, group.Name)
-		if err := uc.neo4jRepo.CreateSubscribeUserGroupRelationship(ctx, user, group); err != nil {
-			return err
-		}
+// SaveUser Сохраняет пользователя вместе с вложенными подписчиками и подписками в одной транзакции.
+func (uc *UserUsecase) SaveUser(ctx context.Context, user models.User) error {
+	if user.ID == 0 {
+		return nil
 	}
 
-	return nil
+	b := newGraphBuilder()
+	b.addUserTree(user)
+
+	return uc.saveGraph(ctx, b.graph)
 }
 
+// SaveGroup Сохраняет группу и подписки на нее. Подписчики должны уже существовать.
 func (uc *UserUsecase) SaveGroup(ctx context.Context, group models.GroupWithSubscribers) error {
 	if group.ID == 0 {
 		return nil
 	}
 
-	if err := uc.neo4jRepo.CreateGroup(ctx, group.Group); err != nil {
-		return err
-	}
-
-	for i := range group.Subscribers {
-		if err := uc.neo4jRepo.CreateSubscribeUserGroupRelationship(ctx, group.Subscribers[i], group.Group); err != nil {
-			return err
-		}
+	b := newGraphBuilder()
+	b.addGroup(group.Group)
+	for _, subscriber := range group.Subscribers {
+		b.addRelationship(models.RelationshipSubscribe, subscriber.ID, group.ID, models.LabelGroup)
 	}
 
-	return nil
-}
-
-func (uc *UserUsecase) GetUsersCount(ctx context.Context) (int, error) {
-	return uc.neo4jRepo.GetUsersCount(ctx)
+	return uc.saveGraph(ctx, b.graph)
 }
 
-func (uc *UserUsecase) GetGroupsCount(ctx context.Context) (int, error) {
-	return uc.neo4jRepo.GetGroupsCount(ctx)
-}
-
-func (uc *UserUsecase) GetTopUsersByFollowersCount(ctx context.Context, limit int) ([]models.User, error) {
-	return uc.neo4jRepo.GetTopUsersByFollowersCount(ctx, limit)
-}
-
-func (uc *UserUsecase) GetTopGroupsBySubscribersCount(ctx context.Context, limit int) ([]models.Group, error) {
-	return uc.neo4jRepo.GetTopGroupsBySubscribersCount(ctx, limit)
-}
+func (uc *UserUsecase) saveGraph(ctx context.Context, graph models.Graph) error {
+	log.Info().Msgf("Сохранение графа: пользователей %d, групп %d, связей %d",
+		len(graph.Users), len(graph.Groups), len(graph.Relationships))
 
-func (uc *UserUsecase) GetUsersWithDifferentGroups(ctx context.Context, limit int) ([]models.User, error) {
-	return uc.neo4jRepo.GetUsersWithDifferentGroups(ctx, limit)
-}
+	if err := uc.repo.SaveGraph(ctx, graph); err != nil {
+		return fmt.Errorf("uc.repo.SaveGraph: %w", err)
+	}
 
-func (uc *UserUsecase) GetAllNodes(ctx context.Context) ([]models.Node, error) {
-	return uc.neo4jRepo.GetAllNodes(ctx)
+	return nil
 }
 
-func (uc *UserUsecase) GetNodeWithRelationships(ctx context.Context, id uint64) (interface{}, error) {
-	return uc.neo4jRepo.GetNodeWithRelationships(ctx, id)
+func (uc *UserUsecase) GetNodeWithRelationships(ctx context.Context, ref models.NodeRef) (interface{}, error) {
+	return uc.repo.GetNodeWithRelationships(ctx, ref)
 }
 
-func (uc *UserUsecase) DeleteNode(ctx context.Context, id uint64) error {
-	return uc.neo4jRepo.DeleteNode(ctx, id)
+func (uc *UserUsecase) DeleteNode(ctx context.Context, ref models.NodeRef) error {
+	return uc.repo.DeleteNode(ctx, ref)
 }
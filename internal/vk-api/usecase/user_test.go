@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSaveUser(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserMemoryRepo()
+	uc := NewUserUsecase(nil, repo, nil)
+
+	group := models.Group{ID: 100, Name: "Group"}
+	err := uc.SaveUser(ctx, models.User{
+		ID:        1,
+		FirstName: "Root",
+		Followers: []models.User{
+			{ID: 2, Subscriptions: models.Subscriptions{Groups: []models.Group{group}}},
+			{ID: 3, Followers: []models.User{{ID: 2, FirstName: "Second"}}},
+			{ID: 0},
+		},
+		Subscriptions: models.Subscriptions{
+			Users:  []models.User{{ID: 2}},
+			Groups: []models.Group{group},
+		},
+	})
+	require.NoError(t, err)
+
+	users, err := repo.GetUsersCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, users)
+
+	groups, err := repo.GetGroupsCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, groups)
+
+	node, err := repo.GetNodeWithRelationships(ctx, models.NodeRef{Label: models.LabelUser, ID: 1})
+	require.NoError(t, err)
+
+	root := node.(models.User)
+	require.Len(t, root.Followers, 2)
+	require.Len(t, root.Subscriptions.Users, 1)
+	require.Equal(t, []models.Group{group}, root.Subscriptions.Groups)
+
+	top, err := repo.GetTopGroupsBySubscribersCount(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []models.TopGroup{{Group: group, SubscribersCount: 2}}, top)
+}
+
+func TestGraphBuilderDeduplicates(t *testing.T) {
+	b := newGraphBuilder()
+	b.addUserTree(models.User{
+		ID: 1,
+		Followers: []models.User{
+			{ID: 2},
+			{ID: 2, FirstName: "Latest"},
+		},
+	})
+
+	require.Equal(t, []models.User{{ID: 1}, {ID: 2, FirstName: "Latest"}}, b.graph.Users)
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 2, TargetID: 1, TargetLabel: models.LabelUser},
+	}, b.graph.Relationships)
+}
@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLimiterWait(t *testing.T) {
+	l := New(20, 1)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := l.Wait(context.Background())
+		require.NoError(t, err)
+	}
+
+	// Первый запрос проходит сразу, остальные четыре - по одному раз в 50мс.
+	require.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	l := New(1, 1)
+	require.True(t, l.Allow())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := l.Wait(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNilLimiter(t *testing.T) {
+	l := New(0, 0)
+	require.Nil(t, l)
+
+	waited, err := l.Wait(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, waited)
+	require.True(t, l.Allow())
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/avast/retry-go"
@@ -31,6 +32,7 @@ func GetRestClient(retryableFunc retry.RetryableFunc) error {
 		retry.RetryIf(checkTempError),
 		retry.DelayType(retry.BackOffDelay),
 		retry.Attempts(attemptsRestCount),
+		retry.LastErrorOnly(true),
 	)
 }
 
@@ -41,11 +43,25 @@ func SendRestClient(retryableFunc retry.RetryableFunc) error {
 		retry.RetryIf(checkTempError),
 		retry.DelayType(retry.BackOffDelay),
 		retry.Attempts(attemptsRestCount),
+		retry.LastErrorOnly(true),
 	)
 }
 
+// apiError Ошибка из ответа API, сама сообщающая, является ли она временной. Реализуется VKError:
+// импортировать его сюда нельзя, пакет VK клиента сам зависит от pkg/rest. *url.Error и *net.OpError
+// тоже умеют Temporary, но для отказа в соединении отвечают false, поэтому проверяются по тексту ниже.
+type apiError interface {
+	Temporary() bool
+	APIError()
+}
+
 // checkTempError Определяет временные ошибки.
 func checkTempError(err error) bool {
+	var apiErr apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
 	return strings.Contains(err.Error(), ErrContextTimeoutSubstr) || strings.Contains(err.Error(), ErrIoTimeoutSubstr) ||
 		strings.Contains(err.Error(), connPriceRefusedError) || strings.Contains(err.Error(), connResetError) ||
 		strings.Contains(err.Error(), connWriteResetError) || strings.Contains(err.Error(), errTLStimeoutSubstr) ||
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAPIError struct {
+	temporary bool
+}
+
+func (e testAPIError) Error() string   { return "api error" }
+func (e testAPIError) Temporary() bool { return e.temporary }
+func (e testAPIError) APIError()       {}
+
+func TestCheckTempError(t *testing.T) {
+	require.True(t, checkTempError(fmt.Errorf("call: %w", testAPIError{temporary: true})))
+	require.False(t, checkTempError(fmt.Errorf("call: %w", testAPIError{})))
+	require.False(t, checkTempError(errors.New("invalid request")))
+
+	// *url.Error отвечает Temporary() == false, но отказ в соединении повторяется.
+	_, err := http.Get("http://127.0.0.1:1/")
+	require.Error(t, err)
+	require.True(t, checkTempError(err))
+
+	attempts := 0
+	err = GetRestClient(func() error {
+		attempts++
+		_, err := http.Get("http://127.0.0.1:1/")
+		return err
+	})
+	require.Error(t, err)
+	require.Equal(t, attemptsRestCount, attempts)
+}
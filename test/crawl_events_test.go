@@ -0,0 +1,30 @@
+package test
+
+import (
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// TestCrawlEventsAccessToken EventSource не передает заголовки: токен принимается параметром access_token.
+func TestCrawlEventsAccessToken(t *testing.T) {
+	client := resty.New().SetBaseURL(apiURL)
+
+	resp, err := client.R().Get("crawls/unknown/events")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	resp, err = client.R().SetQueryParam("access_token", "invalid").Get("crawls/unknown/events")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	resp, err = client.R().SetQueryParam("access_token", token).Get("crawls/unknown/events")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	// Остальные маршруты редактора токен из параметра не принимают.
+	resp, err = client.R().SetQueryParam("access_token", token).Get("crawls")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+}
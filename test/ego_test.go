@@ -0,0 +1,91 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestEgoNetwork(t *testing.T) {
+	client := apiClient()
+
+	// 7903 -> 7902 -> 7901 (Follow), 7901 подписан на группу 7900.
+	resp, err := client.R().
+		SetBody(models.User{
+			ID:            7901,
+			Followers:     []models.User{{ID: 7902}},
+			Subscriptions: models.Subscriptions{Groups: []models.Group{{ID: 7900, Name: "Ego"}}},
+		}).
+		Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	resp, err = client.R().SetBody(models.User{ID: 7902, Followers: []models.User{{ID: 7903}}}).Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	var ego models.Subgraph
+	resp, err = client.R().SetResult(&ego).Get("nodes/users/7901/ego")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7901, 7900, 7902}, nodeIDs(ego.Nodes))
+	require.Equal(t, "Ego", ego.Nodes[1].Properties["name"])
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 7902, TargetID: 7901, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipSubscribe, SourceID: 7901, TargetID: 7900, TargetLabel: models.LabelGroup},
+	}, ego.Relationships)
+	require.False(t, ego.Truncated)
+
+	center := ego.Nodes[0].ElementID
+
+	ego = models.Subgraph{}
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"hops": "2", "types": "Follow"}).
+		SetResult(&ego).
+		Get("nodes/" + url.PathEscape(center) + "/ego")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7901, 7902, 7903}, nodeIDs(ego.Nodes))
+	require.Len(t, ego.Relationships, 2)
+
+	ego = models.Subgraph{}
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"hops": "2", "limit": "2"}).
+		SetResult(&ego).
+		Get("nodes/groups/7900/ego")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7900, 7901}, nodeIDs(ego.Nodes))
+	require.True(t, ego.Truncated)
+
+	for _, tc := range []struct {
+		path   string
+		params map[string]string
+		status int
+	}{
+		{"nodes/users/7901/ego", map[string]string{"hops": "4"}, http.StatusBadRequest},
+		{"nodes/users/7901/ego", map[string]string{"hops": "x"}, http.StatusBadRequest},
+		{"nodes/users/7901/ego", map[string]string{"limit": "0"}, http.StatusBadRequest},
+		{"nodes/users/7901/ego", map[string]string{"types": "Like"}, http.StatusBadRequest},
+		{"nodes/users/7901/ego", map[string]string{"direction": "up"}, http.StatusBadRequest},
+		{"nodes/pages/7901/ego", nil, http.StatusBadRequest},
+		{"nodes/users/7999/ego", nil, http.StatusNotFound},
+	} {
+		resp, err = client.R().SetQueryParams(tc.params).Get(tc.path)
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode(), "%s %v", tc.path, tc.params)
+	}
+
+	for _, id := range []uint64{7901, 7902, 7903} {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/7900")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
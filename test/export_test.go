@@ -0,0 +1,95 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	client := apiClient()
+
+	resp, err := client.R().
+		SetBody(models.User{
+			ID:            8001,
+			FirstName:     "Export",
+			City:          models.City{Title: "Nadym"},
+			Followers:     []models.User{{ID: 8002, City: models.City{Title: "Nadym"}}},
+			Subscriptions: models.Subscriptions{Groups: []models.Group{{ID: 8000, Name: "Exported"}}},
+		}).
+		Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	resp, err = client.R().SetQueryParams(map[string]string{"format": "dot", "city": "Nadym"}).Get("export")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "text/vnd.graphviz", resp.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="vk-graph.dot"`, resp.Header().Get("Content-Disposition"))
+	require.Equal(t, `digraph vk {
+	"u8001" [label="Export ", shape=ellipse, kind="User", vk_id=8001, name="Export ", screen_name="", sex="0", city="Nadym"];
+	"u8002" [label=" ", shape=ellipse, kind="User", vk_id=8002, name=" ", screen_name="", sex="0", city="Nadym"];
+	"u8002" -> "u8001" [label="Follow"];
+}`, resp.String())
+
+	resp, err = client.R().Get("export")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "application/xml", resp.Header().Get("Content-Type"))
+	require.True(t, strings.HasPrefix(resp.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<graphml"))
+	require.Contains(t, resp.String(), `<node id="g8000">`)
+
+	resp, err = client.R().SetQueryParams(map[string]string{"format": "csv", "city": "Nadym", "types": "Subscribe"}).Get("export")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "application/zip", resp.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(resp.Body()), int64(len(resp.Body())))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+
+	f, err := archive.File[0].Open()
+	require.NoError(t, err)
+	nodes, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+
+	f, err = archive.File[1].Open()
+	require.NoError(t, err)
+	edges, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"source", "target", "type"}}, edges)
+
+	for _, params := range []map[string]string{
+		{"format": "xlsx"},
+		{"label": "Page"},
+		{"types": "Like"},
+		{"sex": "7"},
+	} {
+		resp, err = client.R().SetQueryParams(params).Get("export")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode(), "%v", params)
+		require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	}
+
+	resp, err = resty.New().SetBaseURL(apiURL).R().Get("export")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	for _, id := range []uint64{8001, 8002} {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/8000")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	client := apiClient()
+
+	dump := `{
+		"id": 8101,
+		"first_name": "Imported",
+		"followers": [{"id": 8102, "followers": [{"id": 8101}]}, {"id": 0}],
+		"subscriptions": {"groups": [{"id": 8100, "name": "Imported group"}]}
+	}`
+	edges := "source,target,type\n8103,8101,Follow\nu8102,g8100,\nx,8101,Follow\n"
+
+	upload := func(dryRun bool) *resty.Response {
+		resp, err := client.R().
+			SetQueryParam("dry_run", strconv.FormatBool(dryRun)).
+			SetFileReader("file", "dump.json", strings.NewReader(dump)).
+			SetFileReader("file", "edges.csv", strings.NewReader(edges)).
+			SetResult(&models.ImportReport{}).
+			Post("import")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+
+		return resp
+	}
+
+	expected := models.ImportReport{
+		DryRun:        true,
+		Files:         2,
+		Users:         2,
+		Groups:        1,
+		Relationships: 5,
+		Stubs:         1,
+		Duplicates:    1,
+		Invalid:       2,
+		Errors: []string{
+			"dump.json: record 1.followers[1]: user id is required",
+			`edges.csv: line 4: source: invalid node "x"`,
+		},
+	}
+
+	resp := upload(true)
+	require.Equal(t, &expected, resp.Result())
+
+	resp, err := client.R().Get("users/8101")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	expected.DryRun = false
+	expected.Written = models.ImportProgress{Users: 2, Groups: 1, Stubs: 1, Relationships: 5}
+	resp = upload(false)
+	require.Equal(t, &expected, resp.Result())
+
+	resp, err = client.R().SetResult(&models.User{}).Get("users/8101")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "Imported", resp.Result().(*models.User).FirstName)
+
+	resp, err = client.R().SetResult(&[]models.User{}).Get("users/8101/followers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.ElementsMatch(t, []uint64{8102, 8103}, userIDs(*resp.Result().(*[]models.User)))
+
+	resp, err = client.R().SetResult(&[]models.User{}).Get("groups/8100/subscribers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.ElementsMatch(t, []uint64{8101, 8102}, userIDs(*resp.Result().(*[]models.User)))
+
+	// Тело запроса одним файлом.
+	resp, err = client.R().
+		SetHeader("Content-Type", "text/csv").
+		SetBody("8102,8103\n").
+		SetResult(&models.ImportReport{}).
+		Post("import")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, &models.ImportReport{
+		Files:         1,
+		Relationships: 1,
+		Stubs:         2,
+		Written:       models.ImportProgress{Stubs: 2, Relationships: 1},
+	}, resp.Result())
+
+	resp, err = client.R().SetResult(&[]models.User{}).Get("users/8103/followers")
+	require.NoError(t, err)
+	require.Equal(t, []uint64{8102}, userIDs(*resp.Result().(*[]models.User)))
+
+	for _, req := range []*resty.Request{
+		client.R().SetHeader("Content-Type", "application/json").SetBody(`{"id": `),
+		client.R().SetQueryParam("format", "xml").SetBody("<graph/>"),
+		client.R().SetQueryParam("dry_run", "maybe").SetHeader("Content-Type", "text/csv").SetBody("1,2\n"),
+		client.R().SetFileReader("file", "graph.gexf", strings.NewReader("<gexf/>")),
+	} {
+		resp, err = req.Post("import")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+		require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	}
+
+	resp, err = resty.New().SetBaseURL(apiURL).R().
+		SetHeader("Content-Type", "text/csv").
+		SetBody("8102,8103\n").
+		Post("import")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	for _, id := range []uint64{8101, 8102, 8103} {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/8100")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
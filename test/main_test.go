@@ -0,0 +1,63 @@
+package test
+
+import (
+	"context"
+	"github.com/Nimartemoff/vk-api/cmd/vk-api/config"
+	v1 "github.com/Nimartemoff/vk-api/internal/vk-api/controller/http/v1"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/crawler"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/repo/memory"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest"
+	"github.com/Nimartemoff/vk-api/internal/vk-api/usecase/rest/vkfake"
+	"github.com/go-chi/chi"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// TestMain Если задан API_URL, тесты идут в запущенный сервис. Иначе сервис поднимается
+// в процессе теста с хранилищем в памяти и фейковым VK API, Neo4j и доступ к VK не нужны.
+func TestMain(m *testing.M) {
+	if url := os.Getenv("API_URL"); url != "" {
+		apiURL = url + apiPath
+		baseURL = url + nodesPath
+		os.Exit(m.Run())
+	}
+
+	checkpointDir, err := os.MkdirTemp("", "crawls")
+	if err != nil {
+		panic(err)
+	}
+
+	code := runInMemory(m, checkpointDir)
+	os.RemoveAll(checkpointDir)
+	os.Exit(code)
+}
+
+func runInMemory(m *testing.M, checkpointDir string) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := crawler.NewFileStore(checkpointDir)
+	if err != nil {
+		panic(err)
+	}
+
+	vk := vkfake.NewServer(nil)
+	defer vk.Close()
+
+	client := rest.NewVKClient([]string{vk.URL}, []string{"token"})
+	repo := memory.NewUserMemoryRepo()
+	crawls := crawler.NewManager(ctx, client, repo, store, crawler.Config{}, 0)
+
+	r := chi.NewRouter()
+	v1.NewRouter(&config.Config{Storage: config.StorageMemory}, r, usecase.NewUserUsecase(client, repo, crawls))
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	apiURL = srv.URL + apiPath
+	baseURL = srv.URL + nodesPath
+
+	return m.Run()
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestMutual(t *testing.T) {
+	client := apiClient()
+
+	// 7803 подписан на 7801 и 7802, оба подписаны на группу 7800.
+	group := models.Group{ID: 7800, Name: "Shared"}
+	for _, id := range []uint64{7801, 7802} {
+		resp, err := client.R().
+			SetBody(models.User{
+				ID:            id,
+				Followers:     []models.User{{ID: 7803}, {ID: id + 10}},
+				Subscriptions: models.Subscriptions{Groups: []models.Group{group}},
+			}).
+			Post("users")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode())
+	}
+
+	var mutual models.MutualConnections
+	resp, err := client.R().SetQueryParam("ids", "7801,7802").SetResult(&mutual).Get("mutual")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7801, 7802}, mutual.UserIDs)
+	require.Equal(t, 1, mutual.Followers.Count)
+	require.Equal(t, 3, mutual.Followers.Union)
+	require.InDelta(t, 1.0/3, mutual.Followers.Jaccard, 1e-9)
+	require.Equal(t, []uint64{7803}, userIDs(mutual.Followers.Users))
+	require.Equal(t, models.UserOverlap{Users: []models.User{}}, mutual.Subscriptions)
+	require.Equal(t, models.GroupOverlap{Count: 1, Union: 1, Jaccard: 1, Groups: []models.Group{group}}, mutual.Groups)
+
+	var groups models.GroupOverlap
+	resp, err = client.R().SetQueryParamsFromValues(map[string][]string{"ids": {"7801", "7802"}}).SetResult(&groups).Get("mutual/groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, mutual.Groups, groups)
+
+	var followers models.UserOverlap
+	resp, err = client.R().SetQueryParam("ids", "7801,7802,7803").SetResult(&followers).Get("mutual/followers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, 0, followers.Count)
+	require.Empty(t, followers.Users)
+
+	for _, tc := range []struct {
+		ids    string
+		status int
+	}{
+		{"7801", http.StatusBadRequest},
+		{"7801,7801", http.StatusBadRequest},
+		{"7801,x", http.StatusBadRequest},
+		{"7801,7899", http.StatusNotFound},
+	} {
+		resp, err = client.R().SetQueryParam("ids", tc.ids).Get("mutual/subscriptions")
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode(), tc.ids)
+	}
+
+	for _, id := range []uint64{7801, 7802, 7803, 7811, 7812} {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/7800")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
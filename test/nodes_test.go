@@ -0,0 +1,83 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestGetNodesQuery(t *testing.T) {
+	client := apiClient()
+
+	users := []models.User{
+		{ID: 7501, FirstName: "Alla", Sex: 1, City: models.City{Title: "Kurgan"}},
+		{ID: 7502, FirstName: "Boris", Sex: 2, City: models.City{Title: "Kurgan"}},
+		{ID: 7503, FirstName: "Albert", Sex: 2, City: models.City{Title: "Kurgan"}},
+	}
+	for _, user := range users {
+		resp, err := client.R().SetBody(user).Post("users")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode())
+	}
+
+	var ids []uint64
+	params := map[string]string{"city": "Kurgan", "limit": "2", "sort": "-id"}
+	for {
+		page, err := getNodesPage(params)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Nodes), 2)
+
+		for _, node := range page.Nodes {
+			ids = append(ids, node.ID)
+			require.Nil(t, node.Properties)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		params["cursor"] = page.NextCursor
+	}
+	require.Equal(t, []uint64{7503, 7502, 7501}, ids)
+
+	page, err := getNodesPage(map[string]string{"city": "Kurgan", "name": "al", "sort": "name", "properties": "true"})
+	require.NoError(t, err)
+	require.Empty(t, page.NextCursor)
+	require.Len(t, page.Nodes, 2)
+	require.Equal(t, uint64(7503), page.Nodes[0].ID)
+	require.Equal(t, "Albert ", page.Nodes[0].Properties["name"])
+	require.Equal(t, uint64(7501), page.Nodes[1].ID)
+
+	page, err = getNodesPage(map[string]string{"city": "Kurgan", "sex": "2", "label": models.LabelUser})
+	require.NoError(t, err)
+	require.Len(t, page.Nodes, 2)
+
+	page, err = getNodesPage(map[string]string{"city": "Kurgan", "label": models.LabelGroup})
+	require.NoError(t, err)
+	require.Empty(t, page.Nodes)
+
+	first, err := getNodesPage(map[string]string{"city": "Kurgan", "limit": "1"})
+	require.NoError(t, err)
+
+	for _, params := range []map[string]string{
+		{"label": "Post"},
+		{"sort": "city"},
+		{"limit": "0"},
+		{"limit": "100000"},
+		{"sex": "7"},
+		{"properties": "maybe"},
+		{"cursor": "not a cursor"},
+		{"city": "Kurgan", "sort": "name", "cursor": first.NextCursor},
+	} {
+		resp, err := client.R().SetQueryParams(params).Get("nodes")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode(), "%v", params)
+	}
+
+	for _, user := range users {
+		resp, err := client.R().Delete("users/" + strconv.FormatUint(user.ID, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	client := apiClient()
+
+	// 7703 -> 7702 -> 7701 (Follow), 7701 и 7704 подписаны на группу 7700.
+	for _, id := range []uint64{7701, 7702, 7703, 7704} {
+		resp, err := client.R().SetBody(models.User{ID: id, FirstName: "Path"}).Post("users")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode())
+	}
+
+	resp, err := client.R().SetBody(models.GroupWithSubscribers{Group: models.Group{ID: 7700}}).Post("groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	for _, rel := range []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 7702, TargetID: 7701},
+		{Type: models.RelationshipFollow, SourceID: 7703, TargetID: 7702},
+		{Type: models.RelationshipSubscribe, SourceID: 7701, TargetID: 7700, TargetLabel: models.LabelGroup},
+		{Type: models.RelationshipSubscribe, SourceID: 7704, TargetID: 7700, TargetLabel: models.LabelGroup},
+	} {
+		resp, err = client.R().SetBody(rel).Post("relationships")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode(), "%+v", rel)
+	}
+
+	var paths models.Paths
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"source_id": "7703", "target_id": "7701", "types": "Follow", "direction": "out"}).
+		SetResult(&paths).
+		Get("paths")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, 2, paths.Length)
+	require.Len(t, paths.Paths, 1)
+	require.Equal(t, []uint64{7703, 7702, 7701}, nodeIDs(paths.Paths[0].Nodes))
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 7703, TargetID: 7702, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipFollow, SourceID: 7702, TargetID: 7701, TargetLabel: models.LabelUser},
+	}, paths.Paths[0].Relationships)
+	require.Equal(t, "Path ", paths.Paths[0].Nodes[0].Properties["name"])
+
+	// Против направления связей пути нет.
+	paths = models.Paths{}
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"source_id": "7703", "target_id": "7701", "direction": "in"}).
+		SetResult(&paths).
+		Get("paths")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, 0, paths.Length)
+	require.NotNil(t, paths.Paths)
+	require.Empty(t, paths.Paths)
+
+	paths = models.Paths{}
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"source_id": "7704", "target_id": "7701"}).
+		SetResult(&paths).
+		Get("paths")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Empty(t, paths.Paths)
+
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"source_id": "7704", "target_id": "7701", "via_groups": "true"}).
+		SetResult(&paths).
+		Get("paths")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, 2, paths.Length)
+	require.Equal(t, []uint64{7704, 7700, 7701}, nodeIDs(paths.Paths[0].Nodes))
+
+	for _, tc := range []struct {
+		params map[string]string
+		status int
+	}{
+		{map[string]string{"source_id": "7701", "target_id": "7701"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "x"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "7702", "types": "Like"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "7702", "direction": "up"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "7702", "max_hops": "11"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "7702", "via_groups": "maybe"}, http.StatusBadRequest},
+		{map[string]string{"source_id": "7701", "target_id": "7799"}, http.StatusNotFound},
+	} {
+		resp, err = client.R().SetQueryParams(tc.params).Get("paths")
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode(), "%v", tc.params)
+	}
+
+	for _, id := range []uint64{7701, 7702, 7703, 7704} {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/7700")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
+
+func nodeIDs(nodes []models.Node) []uint64 {
+	ids := make([]uint64, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+
+	return ids
+}
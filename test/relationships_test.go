@@ -0,0 +1,102 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestRelationshipsResource(t *testing.T) {
+	client := apiClient()
+
+	for _, id := range []uint64{7401, 7402} {
+		resp, err := client.R().SetBody(models.User{ID: id}).Post("users")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode())
+	}
+
+	resp, err := client.R().SetBody(models.GroupWithSubscribers{Group: models.Group{ID: 7400}}).Post("groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	for _, rel := range []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 7401, TargetID: 7402},
+		{Type: models.RelationshipSubscribe, SourceID: 7401, TargetID: 7402, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipSubscribe, SourceID: 7401, TargetID: 7400, TargetLabel: models.LabelGroup},
+		{Type: models.RelationshipSubscribe, SourceID: 7402, TargetID: 7400, TargetLabel: models.LabelGroup},
+	} {
+		resp, err = client.R().SetBody(rel).Post("relationships")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode(), "%+v", rel)
+	}
+
+	for _, tc := range []struct {
+		rel    models.Relationship
+		status int
+	}{
+		{models.Relationship{Type: models.RelationshipFollow, SourceID: 7401, TargetID: 7400, TargetLabel: models.LabelGroup}, http.StatusBadRequest},
+		{models.Relationship{Type: "Likes", SourceID: 7401, TargetID: 7402}, http.StatusBadRequest},
+		{models.Relationship{Type: models.RelationshipFollow, SourceID: 7401, TargetID: 7499}, http.StatusNotFound},
+		{models.Relationship{Type: models.RelationshipSubscribe, SourceID: 7401, TargetID: 7499, TargetLabel: models.LabelGroup}, http.StatusNotFound},
+	} {
+		resp, err = client.R().SetBody(tc.rel).Post("relationships")
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode(), "%+v", tc.rel)
+	}
+
+	var relationships []models.Relationship
+	resp, err = client.R().SetQueryParam("source_id", "7401").SetResult(&relationships).Get("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []models.Relationship{
+		{Type: models.RelationshipFollow, SourceID: 7401, TargetID: 7402, TargetLabel: models.LabelUser},
+		{Type: models.RelationshipSubscribe, SourceID: 7401, TargetID: 7400, TargetLabel: models.LabelGroup},
+		{Type: models.RelationshipSubscribe, SourceID: 7401, TargetID: 7402, TargetLabel: models.LabelUser},
+	}, relationships)
+
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"type": models.RelationshipSubscribe, "target_id": "7400", "target_label": models.LabelGroup}).
+		SetResult(&relationships).
+		Get("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Len(t, relationships, 2)
+
+	resp, err = client.R().SetQueryParam("type", "Likes").Get("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	deleteQuery := map[string]string{"type": models.RelationshipSubscribe, "source_id": "7401", "target_id": "7400", "target_label": models.LabelGroup}
+	resp, err = client.R().SetQueryParams(deleteQuery).Delete("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().SetQueryParams(deleteQuery).Delete("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	// Узлы и остальные связи остаются.
+	var subscribers []models.User
+	resp, err = client.R().SetResult(&subscribers).Get("groups/7400/subscribers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7402}, userIDs(subscribers))
+
+	resp, err = client.R().
+		SetQueryParams(map[string]string{"type": models.RelationshipFollow, "source_id": "7401", "target_id": "7402"}).
+		Delete("relationships")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var followers []models.User
+	_, err = client.R().SetResult(&followers).Get("users/7402/followers")
+	require.NoError(t, err)
+	require.Empty(t, followers)
+
+	for _, path := range []string{"users/7401", "users/7402", "groups/7400"} {
+		resp, err = client.R().Delete(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode(), path)
+	}
+}
@@ -0,0 +1,182 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// apiClient Клиент API с токеном редактора. Ответы 4xx не повторяются.
+func apiClient() *resty.Client {
+	return resty.New().
+		SetRetryCount(clientRetryCount).
+		SetRetryWaitTime(clientRetryWaitTime).
+		SetRetryMaxWaitTime(clientRetryMaxWaitTime).
+		SetAuthToken(token).
+		SetBaseURL(apiURL)
+}
+
+func TestUsersResource(t *testing.T) {
+	client := apiClient()
+
+	var created models.User
+	resp, err := client.R().
+		SetBody(models.User{
+			ID:        7001,
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			Sex:       2,
+			City:      models.City{Title: "Surgut"},
+			Followers: []models.User{{ID: 7002, FirstName: "Follower"}},
+			Subscriptions: models.Subscriptions{
+				Users:  []models.User{{ID: 7003, FirstName: "Idol"}},
+				Groups: []models.Group{{ID: 7100, Name: "Club"}},
+			},
+		}).
+		SetResult(&created).
+		Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+	require.Equal(t, "/api/v1/users/7001", resp.Header().Get("Location"))
+	require.Equal(t, "Ivan", created.FirstName)
+	require.Nil(t, created.Followers)
+
+	var users []models.User
+	resp, err = client.R().SetResult(&users).Get("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Subset(t, userIDs(users), []uint64{7001, 7002, 7003})
+
+	var followers []models.User
+	resp, err = client.R().SetResult(&followers).Get("users/7001/followers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7002}, userIDs(followers))
+
+	var subscriptions models.Subscriptions
+	resp, err = client.R().SetResult(&subscriptions).Get("users/7001/subscriptions")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7003}, userIDs(subscriptions.Users))
+	require.Equal(t, []models.Group{{ID: 7100, Name: "Club"}}, subscriptions.Groups)
+
+	var user models.User
+	resp, err = client.R().SetBody(`{"city": {"title": "Tyumen"}}`).SetResult(&user).Patch("users/7001")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "Ivan", user.FirstName)
+	require.Equal(t, "Tyumen", user.City.Title)
+
+	resp, err = client.R().SetBody(models.User{FirstName: "Pyotr"}).SetResult(&user).Put("users/7001")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, models.User{ID: 7001, FirstName: "Pyotr"}, user)
+
+	resp, err = client.R().SetBody(models.User{ID: 7002}).Put("users/7001")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	// Связи не меняются при обновлении свойств.
+	_, err = client.R().SetResult(&followers).Get("users/7001/followers")
+	require.NoError(t, err)
+	require.Equal(t, []uint64{7002}, userIDs(followers))
+
+	resp, err = client.R().Delete("users/7001")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	for _, path := range []string{"users/7001", "users/7001/followers", "users/7001/subscriptions"} {
+		resp, err = client.R().Get(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode(), path)
+	}
+
+	resp, err = client.R().Delete("users/7001")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	resp, err = client.R().Get("users/abc")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestGroupsResource(t *testing.T) {
+	client := apiClient()
+
+	resp, err := client.R().SetBody(models.User{ID: 7201}).Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	var group models.Group
+	resp, err = client.R().
+		SetBody(models.GroupWithSubscribers{
+			Group:       models.Group{ID: 7200, Name: "Board games", ScreenName: "boardgames"},
+			Subscribers: []models.User{{ID: 7201}, {ID: 7202}},
+		}).
+		SetResult(&group).
+		Post("groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+	require.Equal(t, models.Group{ID: 7200, Name: "Board games", ScreenName: "boardgames"}, group)
+
+	var groups []models.Group
+	resp, err = client.R().SetResult(&groups).Get("groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Contains(t, groups, group)
+
+	// Подписчик 7202 не существует, поэтому подписка на него не создается.
+	var subscribers []models.User
+	resp, err = client.R().SetResult(&subscribers).Get("groups/7200/subscribers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []uint64{7201}, userIDs(subscribers))
+
+	resp, err = client.R().SetBody(`{"name": "Chess"}`).SetResult(&group).Patch("groups/7200")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, models.Group{ID: 7200, Name: "Chess", ScreenName: "boardgames"}, group)
+
+	resp, err = client.R().SetBody(models.Group{Name: "Go"}).SetResult(&group).Put("groups/7200")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, models.Group{ID: 7200, Name: "Go"}, group)
+
+	resp, err = client.R().SetBody(models.Group{Name: "Go"}).Put("groups/7300")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	resp, err = client.R().Delete("groups/7200")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().Get("groups/7200/subscribers")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	var subscriptions models.Subscriptions
+	resp, err = client.R().SetResult(&subscriptions).Get("users/7201/subscriptions")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Empty(t, subscriptions.Groups)
+
+	resp, err = client.R().Delete("users/7201")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}
+
+func TestResourcesRequireEditor(t *testing.T) {
+	resp, err := apiClient().SetAuthToken("").R().SetBody(models.User{ID: 7301}).Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+}
+
+func userIDs(users []models.User) []uint64 {
+	ids := make([]uint64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"github.com/Nimartemoff/vk-api/internal/vk-api/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	client := apiClient()
+
+	var before models.Counts
+	resp, err := client.R().SetResult(&before).Get("stats/counts")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	// У 7601 пять подписчиков, все подписаны на группу 7600.
+	group := models.Group{ID: 7600, Name: "Popular"}
+	user := models.User{ID: 7601, FirstName: "Star"}
+	for id := uint64(7602); id <= 7606; id++ {
+		user.Followers = append(user.Followers, models.User{
+			ID:            id,
+			Subscriptions: models.Subscriptions{Groups: []models.Group{group}},
+		})
+	}
+
+	resp, err = client.R().SetBody(user).Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	var after models.Counts
+	resp, err = client.R().SetResult(&after).Get("stats/counts")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, models.Counts{Users: before.Users + 6, Groups: before.Groups + 1}, after)
+
+	var users []models.User
+	var topUsers []models.TopUser
+	resp, err = client.R().SetQueryParam("limit", "1").SetResult(&topUsers).Get("stats/top-users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Len(t, topUsers, 1)
+	require.Equal(t, uint64(7601), topUsers[0].ID)
+	require.Equal(t, 5, topUsers[0].FollowersCount)
+
+	var groups []models.TopGroup
+	resp, err = client.R().SetQueryParam("limit", "1").SetResult(&groups).Get("stats/top-groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, []models.TopGroup{{Group: group, SubscribersCount: 5}}, groups)
+
+	// Группа 7699 только у 7607, группу 7600 делят подписчики 7601.
+	loner := models.User{ID: 7607, Subscriptions: models.Subscriptions{Groups: []models.Group{{ID: 7699}}}}
+	resp, err = client.R().SetBody(loner).Post("users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	resp, err = client.R().SetResult(&users).Get("stats/users-with-different-groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.LessOrEqual(t, len(users), 5)
+
+	resp, err = client.R().SetQueryParam("limit", "100").SetResult(&users).Get("stats/users-with-different-groups")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Contains(t, userIDs(users), uint64(7607))
+	require.NotContains(t, userIDs(users), uint64(7602))
+
+	for _, path := range []string{"stats/top-users", "stats/top-groups", "stats/users-with-different-groups"} {
+		for _, limit := range []string{"0", "-1", "1000", "five"} {
+			var body struct {
+				Error string `json:"error"`
+			}
+			resp, err = client.R().SetQueryParam("limit", limit).SetError(&body).Get(path)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode(), "%s?limit=%s", path, limit)
+			require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+			require.NotEmpty(t, body.Error)
+		}
+	}
+
+	for id := uint64(7601); id <= 7606; id++ {
+		resp, err = client.R().Delete("users/" + strconv.FormatUint(id, base))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = client.R().Delete("groups/7600")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+}